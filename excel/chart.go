@@ -0,0 +1,139 @@
+package excel
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+)
+
+type ChartType string
+
+const (
+	ChartLine ChartType = "line"
+	// ChartBar 横向条形图
+	ChartBar ChartType = "bar"
+	// ChartCol 纵向柱状图
+	ChartCol  ChartType = "col"
+	ChartArea ChartType = "area"
+	ChartPie  ChartType = "pie"
+)
+
+var chartTypes = map[ChartType]excelize.ChartType{
+	ChartLine: excelize.Line,
+	ChartBar:  excelize.Bar,
+	ChartCol:  excelize.Col,
+	ChartArea: excelize.Area,
+	ChartPie:  excelize.Pie,
+}
+
+// ChartSpec 图表定义.
+// Category/Values 可以是列名, 也可以是 WriteStructs 写入时的结构体字段名.
+type ChartSpec struct {
+	// DataSheet 数据所在 sheet, 必须已由 Writer 写入
+	DataSheet string
+	// Sheet 图表所在 sheet, 默认 DataSheet, 不存在时自动创建
+	Sheet string
+	// Cell 图表左上角, 默认放在数据区域右侧
+	Cell string
+
+	Type  ChartType
+	Title string
+
+	// Category 分类轴(x 轴)
+	Category string
+	// Values 数值系列, 每个一条线/一组柱, 系列名取列名
+	Values []string
+
+	// Width/Height 像素, 默认使用 excelize 的默认值
+	Width  uint
+	Height uint
+}
+
+// AddChart 基于已写入的数据 sheet 生成 excel 原生图表
+func (w *Writer) AddChart(spec ChartSpec) error {
+	chartType, ok := chartTypes[spec.Type]
+	if !ok {
+		return errors.Errorf("ChartSpec.Type(%s) not supported", spec.Type)
+	}
+	if spec.Category == "" {
+		return errors.New("ChartSpec.Category empty")
+	}
+	if len(spec.Values) == 0 {
+		return errors.New("ChartSpec.Values empty")
+	}
+
+	ws, exist := w.sheets[spec.DataSheet]
+	if !exist {
+		return errors.Errorf("sheet(%s) not written", spec.DataSheet)
+	}
+	if ws.rowCount == 0 {
+		return errors.Errorf("sheet(%s) has no data", spec.DataSheet)
+	}
+	firstRow, lastRow := 2, ws.rowCount+1
+
+	catIdx, err := w.columnIndex(spec.DataSheet, spec.Category)
+	if err != nil {
+		return err
+	}
+	categories, err := cellRange(spec.DataSheet, catIdx+1, firstRow, catIdx+1, lastRow)
+	if err != nil {
+		return err
+	}
+
+	chart := &excelize.Chart{
+		Type: chartType,
+		Dimension: excelize.ChartDimension{
+			Width:  spec.Width,
+			Height: spec.Height,
+		},
+	}
+	if spec.Title != "" {
+		chart.Title = []excelize.RichTextRun{{Text: spec.Title}}
+	}
+
+	for _, v := range spec.Values {
+		valIdx, err := w.columnIndex(spec.DataSheet, v)
+		if err != nil {
+			return err
+		}
+		titleCell, err := excelize.CoordinatesToCellName(valIdx+1, 1, true)
+		if err != nil {
+			return err
+		}
+		values, err := cellRange(spec.DataSheet, valIdx+1, firstRow, valIdx+1, lastRow)
+		if err != nil {
+			return err
+		}
+		chart.Series = append(chart.Series, excelize.ChartSeries{
+			Name:       sheetRef(spec.DataSheet) + "!" + titleCell,
+			Categories: categories,
+			Values:     values,
+		})
+	}
+
+	sheetName := spec.Sheet
+	if sheetName == "" {
+		sheetName = spec.DataSheet
+	}
+	if _, exist := w.sheets[sheetName]; !exist {
+		if err := w.ensureSheet(sheetName); err != nil {
+			return err
+		}
+	}
+
+	cell := spec.Cell
+	if cell == "" {
+		cell = "A1"
+		if sheetName == spec.DataSheet {
+			// 数据区域右侧空一列
+			cell, err = excelize.CoordinatesToCellName(len(ws.titles)+2, 1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.file.AddChart(sheetName, cell, chart); err != nil {
+		return errors.Wrapf(err, "AddChart(%s,%s)", sheetName, cell)
+	}
+	return nil
+}
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	excelPkg       = "github.com/JfL0unch/goUtil/excel"
+	reflectUtilPkg = "github.com/JfL0unch/goUtil/reflectUtils"
+
+	keyFromTag       = "tag"
+	keyFromFieldName = "field_name"
+
+	defaultPositionTag = "excel"
+	generatedSuffix    = "_excelgen.go"
+)
+
+// Config 一次生成的参数, 含义与 excel.ReaderConfig 对应
+type Config struct {
+	// Dir 结构体所在的包目录
+	Dir string
+	// Type 结构体类型名
+	Type string
+	// KeyFrom tag 或 field_name, 同 excel.KeyFrom
+	KeyFrom string
+	// Tag 同 excel.ReaderConfig.KeyTagName, 为空时为 json
+	Tag string
+	// Position 按位置 tag 对应列, 同 excel.ReaderConfig.SheetWithTitle=false
+	Position bool
+	// PositionTag 同 excel.ReaderConfig.PositionTagName, 为空时为 excel
+	PositionTag string
+}
+
+func (c Config) tag() string {
+	if c.Tag == "" {
+		return "json"
+	}
+	return c.Tag
+}
+
+func (c Config) positionTag() string {
+	if c.PositionTag == "" {
+		return defaultPositionTag
+	}
+	return c.PositionTag
+}
+
+// args 写入生成文件头部的命令行参数
+func (c Config) args() string {
+	args := []string{"-type=" + c.Type}
+	if c.KeyFrom != "" && c.KeyFrom != keyFromTag {
+		args = append(args, "-key="+c.KeyFrom)
+	}
+	if c.Tag != "" {
+		args = append(args, "-tag="+c.Tag)
+	}
+	if c.Position {
+		args = append(args, "-position")
+	}
+	if c.PositionTag != "" {
+		args = append(args, "-position-tag="+c.PositionTag)
+	}
+	return strings.Join(args, " ")
+}
+
+// OutputName 默认的输出文件名, 如 order_excelgen.go
+func OutputName(typeName string) string {
+	return strings.ToLower(typeName) + generatedSuffix
+}
+
+// field 打平后的一个导出字段
+type field struct {
+	// Path 字段名路径, 如 Base.Id, 与 reflectUtils.FieldInfo.Path 一致
+	Path string
+	Name string
+	// Type 字段类型的源码
+	Type string
+	// Basic 类型属于 reflectUtils.Basic
+	Basic bool
+	// Media excel.Image/excel.Link, 解析时跳过
+	Media bool
+	Key   string
+
+	Column    int
+	HasColumn bool
+}
+
+// column 生成代码中的一列
+type column struct {
+	Label string
+	Index int
+	*field
+}
+
+type typeSpec struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+type generator struct {
+	c     Config
+	fset  *token.FileSet
+	pkg   string
+	types map[string]typeSpec
+	// imports 字段类型用到的包, path -> 导入名(可为空)
+	imports map[string]string
+	fields  []*field
+}
+
+var basicTypes = map[string]bool{
+	"string": true, "bool": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true,
+	"byte": true, "rune": true,
+}
+
+// Generate 生成 c.Type 的 Codec 源码
+func Generate(c Config) ([]byte, error) {
+	if c.KeyFrom == "" {
+		c.KeyFrom = keyFromTag
+	}
+	if c.KeyFrom != keyFromTag && c.KeyFrom != keyFromFieldName {
+		return nil, errors.Errorf("invalid key(%s), should be %s or %s", c.KeyFrom, keyFromTag, keyFromFieldName)
+	}
+
+	g := &generator{c: c, fset: token.NewFileSet(), types: map[string]typeSpec{}, imports: map[string]string{}}
+	if err := g.parseDir(); err != nil {
+		return nil, err
+	}
+	ts, ok := g.types[c.Type]
+	if !ok {
+		return nil, errors.Errorf("type %s not found in %s", c.Type, c.Dir)
+	}
+	st, ok := ts.spec.Type.(*ast.StructType)
+	if !ok || ts.spec.TypeParams != nil {
+		return nil, errors.Errorf("type %s is not a non-generic struct", c.Type)
+	}
+	if err := g.walk(st, ts.file, ""); err != nil {
+		return nil, errors.Wrapf(err, "type %s", c.Type)
+	}
+	if c.Position {
+		if err := g.positions(); err != nil {
+			return nil, errors.Wrapf(err, "type %s", c.Type)
+		}
+	}
+	return g.render()
+}
+
+func (g *generator) parseDir() error {
+	entries, err := os.ReadDir(g.c.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "ReadDir(%s)", g.c.Dir)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, generatedSuffix) {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, filepath.Join(g.c.Dir, name), nil, 0)
+		if err != nil {
+			return errors.Wrapf(err, "ParseFile(%s)", name)
+		}
+		g.pkg = f.Name.Name
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				g.types[ts.Name.Name] = typeSpec{spec: ts, file: f}
+			}
+		}
+	}
+	if g.pkg == "" {
+		return errors.Errorf("no go files in %s", g.c.Dir)
+	}
+	return nil
+}
+
+// walk 与 reflectUtils.CachedFields 相同: 按顺序遍历, Anonymous 的结构体字段被打平, 只保留导出字段
+func (g *generator) walk(st *ast.StructType, file *ast.File, path string) error {
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			tag, _ = strconv.Unquote(f.Tag.Value)
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			name, embedded, err := g.embedded(f.Type)
+			if err != nil {
+				return err
+			}
+			if embedded != nil {
+				if err := g.walk(embedded.st, embedded.file, joinPath(path, name)); err != nil {
+					return err
+				}
+				continue
+			}
+			names = append(names, name)
+		}
+
+		for _, name := range names {
+			if !ast.IsExported(name) {
+				continue
+			}
+			fd, err := g.newField(f.Type, file, name, reflect.StructTag(tag))
+			if err != nil {
+				return errors.Wrapf(err, "field %s", name)
+			}
+			fd.Path = joinPath(path, name)
+			g.fields = append(g.fields, fd)
+		}
+	}
+	return nil
+}
+
+type embeddedStruct struct {
+	st   *ast.StructType
+	file *ast.File
+}
+
+// embedded Anonymous 字段的名字, 是本包的结构体时返回其定义
+func (g *generator) embedded(expr ast.Expr) (string, *embeddedStruct, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if st, file := g.resolveStruct(t.Name); st != nil {
+			return t.Name, &embeddedStruct{st: st, file: file}, nil
+		}
+		return t.Name, nil, nil
+	case *ast.StarExpr:
+		name, _, err := g.embedded(t.X)
+		return name, nil, err
+	case *ast.SelectorExpr:
+		return "", nil, errors.Errorf("embedded %s.%s from another package not supported", t.X, t.Sel.Name)
+	}
+	return "", nil, errors.Errorf("embedded %T not supported", expr)
+}
+
+// resolveStruct 本包中名为 name 的类型的底层结构体
+func (g *generator) resolveStruct(name string) (*ast.StructType, *ast.File) {
+	for i := 0; i < len(g.types); i++ {
+		ts, ok := g.types[name]
+		if !ok || ts.spec.TypeParams != nil {
+			return nil, nil
+		}
+		switch t := ts.spec.Type.(type) {
+		case *ast.StructType:
+			return t, ts.file
+		case *ast.Ident:
+			name = t.Name
+		default:
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+func (g *generator) newField(expr ast.Expr, file *ast.File, name string, tag reflect.StructTag) (*field, error) {
+	typ, err := g.typeString(expr, file)
+	if err != nil {
+		return nil, err
+	}
+	fd := &field{Name: name, Type: typ}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		_, local := g.types[t.Name]
+		fd.Basic = basicTypes[t.Name] && !local
+		fd.Media = local && g.pkg == "excel" && (t.Name == "Image" || t.Name == "Link")
+	case *ast.SelectorExpr:
+		switch importPath(file, t) {
+		case "time":
+			fd.Basic = t.Sel.Name == "Time" || t.Sel.Name == "Duration"
+		case excelPkg:
+			fd.Media = t.Sel.Name == "Image" || t.Sel.Name == "Link"
+		}
+	}
+
+	if g.c.KeyFrom == keyFromFieldName {
+		fd.Key = name
+	} else {
+		fd.Key = tag.Get(g.c.tag())
+	}
+	if g.c.Position {
+		fd.Column, fd.HasColumn, err = parsePositionTag(tag.Get(g.c.positionTag()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fd, nil
+}
+
+// typeString 字段类型的源码, 同时记录用到的 import
+func (g *generator) typeString(expr ast.Expr, file *ast.File) (string, error) {
+	var err error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		spec := importSpec(file, x.Name)
+		if spec == nil {
+			err = errors.Errorf("unknown package %s", x.Name)
+			return false
+		}
+		path, _ := strconv.Unquote(spec.Path.Value)
+		alias := ""
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		g.imports[path] = alias
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.Buffer{}
+	if err := printer.Fprint(&buf, g.fset, expr); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func importPath(file *ast.File, sel *ast.SelectorExpr) string {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	spec := importSpec(file, x.Name)
+	if spec == nil {
+		return ""
+	}
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importSpec 文件中导入名为 name 的 import, 没有别名时按路径推断包名
+func importSpec(file *ast.File, name string) *ast.ImportSpec {
+	for _, spec := range file.Imports {
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return spec
+			}
+			continue
+		}
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if defaultPackageName(path) == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+// defaultPackageName 如 github.com/xuri/excelize/v2 -> excelize, gopkg.in/yaml.v3 -> yaml
+func defaultPackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if versionSuffix.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// parsePositionTag 同 excel 包的位置 tag: `excel:"idx=3"` 或 `excel:"col=D"`
+func parsePositionTag(tag string) (columnIndex int, ok bool, err error) {
+	if tag == "" || tag == "-" {
+		return 0, false, nil
+	}
+
+	for _, item := range strings.Split(tag, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		switch key {
+		case "idx":
+			i, err := strconv.Atoi(val)
+			if err != nil {
+				return 0, false, errors.Wrapf(err, "invalid idx(%s)", val)
+			}
+			if i < 0 {
+				return 0, false, errors.Errorf("invalid idx(%d)", i)
+			}
+			return i, true, nil
+		case "col":
+			n, err := excelize.ColumnNameToNumber(val)
+			if err != nil {
+				return 0, false, errors.Wrapf(err, "invalid col(%s)", val)
+			}
+			return n - 1, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// positions 位置重复时报错, 与 excel.Reader 一致
+func (g *generator) positions() error {
+	used := map[int]string{}
+	for _, f := range g.fields {
+		if !f.HasColumn {
+			continue
+		}
+		if exist, dup := used[f.Column]; dup {
+			return errors.Errorf("field(%s) column index %d already mapped to field %s", f.Path, f.Column, exist)
+		}
+		used[f.Column] = f.Path
+	}
+	return nil
+}
+
+// parseColumns ParseRow 中的 case, 与 excel.Reader 相同: 同名的列对应最后一个字段,
+// Image/Link 字段和列名为空或 - 的字段跳过
+func (g *generator) parseColumns() []column {
+	res := make([]column, 0, len(g.fields))
+	if g.c.Position {
+		for _, f := range g.fields {
+			if f.HasColumn && !f.Media {
+				res = append(res, column{Label: strconv.Itoa(f.Column), Index: f.Column, field: f})
+			}
+		}
+		sort.Slice(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+		return res
+	}
+
+	byKey := map[string]*field{}
+	for _, f := range g.fields {
+		byKey[f.Key] = f
+	}
+	for _, f := range g.fields {
+		if f.Key == "" || f.Key == "-" {
+			continue
+		}
+		if byKey[f.Key] == f && !f.Media {
+			res = append(res, column{Label: strconv.Quote(f.Key), field: f})
+		}
+	}
+	return res
+}
+
+// formatColumns FormatRow 的列: 按位置, 或与 excel.Writer 相同(列名为空或 - 的字段被忽略)
+func (g *generator) formatColumns() []column {
+	res := make([]column, 0, len(g.fields))
+	for _, f := range g.fields {
+		switch {
+		case g.c.Position && f.HasColumn:
+			res = append(res, column{Index: f.Column, field: f})
+		case !g.c.Position && f.Key != "" && f.Key != "-":
+			res = append(res, column{Index: len(res), field: f})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+	return res
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+type renderData struct {
+	Args    string
+	Package string
+	Imports []string
+	Type    string
+	Codec   string
+	New     string
+	TypeVar string
+	Excel   string
+	Rules   string
+	// Writer 列与之相同的 excel.Writer 配置, 按位置时为空
+	Writer   string
+	Tag      string
+	Position bool
+	Parse    []column
+	Format   []column
+	Width    int
+	Fallback bool
+}
+
+func (g *generator) render() ([]byte, error) {
+	d := renderData{
+		Args:     g.c.args(),
+		Package:  g.pkg,
+		Type:     g.c.Type,
+		Codec:    g.c.Type + "Codec",
+		Tag:      g.c.tag(),
+		Position: g.c.Position,
+		Parse:    g.parseColumns(),
+		Format:   g.formatColumns(),
+	}
+	if ast.IsExported(g.c.Type) {
+		d.New = "New" + d.Codec
+	} else {
+		d.New = "new" + strings.ToUpper(d.Codec[:1]) + d.Codec[1:]
+	}
+	d.TypeVar = strings.ToLower(d.Codec[:1]) + d.Codec[1:] + "Type"
+	if n := len(d.Format); n > 0 {
+		d.Width = d.Format[n-1].Index + 1
+	}
+
+	if g.c.Position {
+		d.Rules = "excel.Reader(SheetWithTitle=false, PositionTagName=" + g.c.positionTag() + ")"
+	} else {
+		d.Rules = "excel.Reader(KeyFrom=" + g.c.KeyFrom + ", KeyTagName=" + g.c.tag() + ")"
+		d.Writer = "excel.Writer(KeyFrom=" + g.c.KeyFrom + ", KeyTagName=" + g.c.tag() + ")"
+	}
+	for _, c := range d.Parse {
+		d.Fallback = d.Fallback || !c.Basic
+	}
+
+	imports := map[string]string{reflectUtilPkg: ""}
+	if g.pkg != "excel" {
+		imports[excelPkg] = ""
+		d.Excel = "excel."
+	}
+	if d.Fallback {
+		imports["reflect"] = ""
+	}
+	for path, alias := range g.imports {
+		imports[path] = alias
+	}
+	for path, alias := range imports {
+		if alias != "" {
+			d.Imports = append(d.Imports, alias+" "+strconv.Quote(path))
+		} else {
+			d.Imports = append(d.Imports, strconv.Quote(path))
+		}
+	}
+	sort.Strings(d.Imports)
+
+	buf := bytes.Buffer{}
+	if err := codecTmpl.Execute(&buf, d); err != nil {
+		return nil, errors.Wrap(err, "template")
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "format.Source\n%s", buf.String())
+	}
+	return src, nil
+}
+
+var codecTmpl = template.Must(template.New("codec").Parse(`// Code generated by excelgen {{.Args}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// {{.Codec}} 不使用反射解析和格式化 {{.Type}} 的行, 解析规则与 {{.Rules}} 相同;
+// 基本类型以外的字段仍通过 reflectUtils 解析和格式化. 零值使用默认选项
+type {{.Codec}} struct {
+	parse  *reflectUtils.ParseOptions
+	format *reflectUtils.FormatOptions
+}
+
+// {{.New}} opts 同 excel.ReaderConfig.ParseOpts
+func {{.New}}(opts ...reflectUtils.ParseOpt) {{.Codec}} {
+	return {{.Codec}}{parse: reflectUtils.NewParseOptions(opts...)}
+}
+
+// WithFormat FormatRow 使用的选项
+func (c {{.Codec}}) WithFormat(opts ...reflectUtils.FormatOpt) {{.Codec}} {
+	c.format = reflectUtils.NewFormatOptions(opts...)
+	return c
+}
+{{if .Fallback}}
+var {{.TypeVar}} = reflect.TypeOf({{.Type}}{})
+{{end}}
+// Titles FormatRow 各列的标题{{if .Writer}}, 与 {{.Writer}} 写入的标题行相同{{end}}
+func (c {{.Codec}}) Titles() {{.Excel}}Titles {
+	return {{.Excel}}Titles{
+{{- range .Format}}
+		{{.Index}}: {{printf "%q" .Key}},
+{{- end}}
+	}
+}
+
+// ParseRow {{if .Position}}按位置 tag{{else}}按 titles{{end}} 把一行解析为 {{.Type}}, 没有对应字段的列被忽略;
+// 所有字段的错误以 reflectUtils.BindErrors 一起返回, 出错的字段为零值
+func (c {{.Codec}}) ParseRow(row []string, titles {{.Excel}}Titles) ({{.Type}}, error) {
+	var v {{.Type}}
+	var errs reflectUtils.BindErrors
+{{- if .Fallback}}
+	fields := reflectUtils.CachedFields({{.TypeVar}}, {{printf "%q" .Tag}})
+{{- end}}
+	for col, s := range row {
+{{- if not .Position}}
+		title, ok := titles[col]
+		if !ok {
+			continue
+		}
+{{- end}}
+		var path string
+		var err error
+		switch {{if .Position}}col{{else}}title{{end}} {
+{{- range .Parse}}
+		case {{.Label}}:
+			path = {{printf "%q" .Path}}
+{{- if .Basic}}
+			v.{{.Path}}, err = reflectUtils.ParseBasic[{{.Type}}](c.parse, s)
+{{- else}}
+			f, _ := fields.ByPath(path)
+			v.{{.Path}}, err = reflectUtils.ParseFieldAs[{{.Type}}](c.parse, f, s)
+{{- end}}
+{{- end}}
+		}
+		if err != nil {
+			errs = append(errs, &reflectUtils.FieldError{Field: path, Key: titles[col], Value: s, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return v, errs
+	}
+	return v, nil
+}
+
+// FormatRow 把 v 格式化为一行, 列与 Titles 对应, 单元格同 reflectUtils.FormatInstanceToStr;
+// 格式化失败的字段为空字符串
+func (c {{.Codec}}) FormatRow(v {{.Type}}) []string {
+	row := make([]string, {{.Width}})
+{{- range .Format}}
+{{- if .Basic}}
+	row[{{.Index}}] = reflectUtils.FormatBasic(c.format, v.{{.Path}})
+{{- else}}
+	row[{{.Index}}], _ = reflectUtils.FormatAs(c.format, v.{{.Path}})
+{{- end}}
+{{- end}}
+	return row
+}
+`))
@@ -0,0 +1,89 @@
+package main
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	Convey("sample is up to date", t, func() {
+		dir := filepath.Join("internal", "sample")
+		for _, c := range []Config{
+			{Dir: dir, Type: "Order"},
+			{Dir: dir, Type: "position", Position: true},
+		} {
+			got, err := Generate(c)
+			So(err, ShouldBeNil)
+			want, err := os.ReadFile(filepath.Join(dir, OutputName(c.Type)))
+			So(err, ShouldBeNil)
+			So(string(got), ShouldEqual, string(want))
+		}
+	})
+
+	Convey("options", t, func() {
+		dir := t.TempDir()
+		src := `package model
+
+import (
+	"time"
+	dec "github.com/shopspring/decimal"
+)
+
+type base struct {
+	Id int
+}
+
+type Image struct{}
+
+type Row struct {
+	base
+	*time.Location
+	Name   string    ` + "`csv:\"name\" excel:\"col=B\"`" + `
+	Price  dec.Decimal ` + "`csv:\"price\" excel:\"idx=0\"`" + `
+	At     time.Time
+	hidden string
+}
+
+type Dup struct {
+	A string ` + "`excel:\"idx=1\"`" + `
+	B string ` + "`excel:\"col=B\"`" + `
+}
+`
+		So(os.WriteFile(filepath.Join(dir, "model.go"), []byte(src), 0644), ShouldBeNil)
+
+		_, err := Generate(Config{Dir: dir, Type: "Row"})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "from another package")
+
+		So(os.WriteFile(filepath.Join(dir, "model.go"), []byte(strings.Replace(src, "*time.Location\n", "", 1)), 0644), ShouldBeNil)
+		out, err := Generate(Config{Dir: dir, Type: "Row", KeyFrom: keyFromFieldName})
+		So(err, ShouldBeNil)
+		s := string(out)
+		So(s, ShouldContainSubstring, `-type=Row -key=field_name;`)
+		So(s, ShouldContainSubstring, `dec "github.com/shopspring/decimal"`)
+		So(s, ShouldContainSubstring, `case "Id":`)
+		So(s, ShouldContainSubstring, `v.base.Id, err = reflectUtils.ParseBasic[int](c.parse, s)`)
+		So(s, ShouldContainSubstring, `v.Price, err = reflectUtils.ParseFieldAs[dec.Decimal](c.parse, f, s)`)
+		So(s, ShouldContainSubstring, `reflectUtils.ParseBasic[time.Time]`)
+		So(s, ShouldNotContainSubstring, `hidden`)
+
+		out, err = Generate(Config{Dir: dir, Type: "Row", Tag: "csv", Position: true})
+		So(err, ShouldBeNil)
+		s = string(out)
+		So(s, ShouldContainSubstring, "case 0:\n\t\t\tpath = \"Price\"")
+		So(s, ShouldContainSubstring, `1: "name",`)
+		So(s, ShouldNotContainSubstring, `At`)
+
+		_, err = Generate(Config{Dir: dir, Type: "Dup", Position: true})
+		So(err, ShouldNotBeNil)
+		_, err = Generate(Config{Dir: dir, Type: "Image"})
+		So(err, ShouldBeNil)
+		_, err = Generate(Config{Dir: dir, Type: "Missing"})
+		So(err, ShouldNotBeNil)
+		_, err = Generate(Config{Dir: dir, Type: "Row", KeyFrom: "x"})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,89 @@
+// Package sample excelgen 生成代码的示例和测试
+package sample
+
+import (
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+//go:generate go run github.com/JfL0unch/goUtil/excel/cmd/excelgen -type=Order
+//go:generate go run github.com/JfL0unch/goUtil/excel/cmd/excelgen -type=position -position
+
+type Status uint8
+
+// Level 实现了 encoding.TextMarshaler/TextUnmarshaler
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	switch l {
+	case 0:
+		return nil, nil
+	case 1:
+		return []byte("low"), nil
+	case 2:
+		return []byte("high"), nil
+	}
+	return nil, errors.Errorf("invalid level %d", l)
+}
+
+func (l *Level) UnmarshalText(b []byte) error {
+	switch strings.ToLower(string(b)) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.Errorf("invalid level %s", b)
+	}
+	return nil
+}
+
+type PayMethod interface {
+	Pay() string
+}
+
+type Card struct {
+	No string `json:"no"`
+}
+
+func (c Card) Pay() string { return "card " + c.No }
+
+func init() {
+	reflectUtils.RegisterVariant[PayMethod]("card", Card{})
+}
+
+type Base struct {
+	Id uint64 `json:"id"`
+}
+
+type Address struct {
+	City string `json:"city"`
+}
+
+type Order struct {
+	Base
+	Name    string         `json:"name"`
+	Point   float64        `json:"point"`
+	Count   int32          `json:"count"`
+	Paid    bool           `json:"paid"`
+	Created time.Time      `json:"created"`
+	Timeout time.Duration  `json:"timeout"`
+	Status  Status         `json:"status"`
+	Level   Level          `json:"level"`
+	Tags    []string       `json:"tags" sep:","`
+	Attrs   map[string]int `json:"attrs"`
+	Address *Address       `json:"address"`
+	Payment PayMethod      `json:"payment"`
+	Memo    string         `json:"-"`
+	note    string
+}
+
+// position 按列的位置解析
+type position struct {
+	Name  string `excel:"idx=0"`
+	Count int    `excel:"col=C"`
+	Ids   []int  `excel:"idx=1" sep:"|"`
+	Other string
+}
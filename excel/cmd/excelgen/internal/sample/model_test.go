@@ -0,0 +1,210 @@
+package sample
+
+import (
+	"github.com/JfL0unch/goUtil/excel"
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	. "github.com/smartystreets/goconvey/convey"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newOrderFixture() *excel.Memory {
+	return excel.NewFixture().
+		Sheet("Sheet1").
+		Header("id", "name", "point", "count", "paid", "created", "timeout", "status", "level", "tags", "attrs", "address", "payment", "-", "other").
+		Row("1", "jack", "17.23", "3", "yes", "2023-08-07 00:34:00", "1h30m", "1", "low", "a,b", `{"x":1}`, `{"city":"sz"}`, `{"type":"card","no":"6222"}`, "memo", "x").
+		Row("2", "tom", "", "", "否", "2023/8/7", "90s", "2", "HIGH", "", "", "", "", "", "").
+		Row("x", "lucy", "1e3", "99999999999", "maybe", "bad", "1y", "300", "mid", "c", "[1]", "{", `{"type":"cash"}`).
+		Row("4").
+		Fixture().
+		Memory("order.xlsx")
+}
+
+// reflectOrders excel.Reader 的结果
+func reflectOrders(source excel.Intf, opts ...reflectUtils.ParseOpt) []Order {
+	r := excel.NewReader(excel.ReaderConfig{
+		SheetWithTitle: true,
+		KeyFrom:        excel.KeyFromTag,
+		KeyTagName:     "json",
+		Source:         source,
+		ParseOpts:      opts,
+	})
+	ret, err := r.Parse(Order{}, "order.xlsx", "Sheet1")
+	So(err, ShouldBeNil)
+	return ret.([]Order)
+}
+
+// codecOrders 生成代码的结果
+func codecOrders(source excel.Intf, codec OrderCodec) ([]Order, []error) {
+	sheet, err := source.GetSheet("order.xlsx", "Sheet1", excel.FirstRowAsTitles())
+	So(err, ShouldBeNil)
+	res := make([]Order, 0, len(sheet.Rows()))
+	errs := make([]error, 0, len(sheet.Rows()))
+	for _, row := range sheet.Rows() {
+		o, err := codec.ParseRow(row, sheet.Titles())
+		res = append(res, o)
+		errs = append(errs, err)
+	}
+	return res, errs
+}
+
+// formatFields FormatInstanceToStr 的结果, 列与 titles 对应, 出错的字段为空字符串
+func formatFields(v interface{}, titles excel.Titles) []string {
+	rv := reflect.ValueOf(v)
+	fields := reflectUtils.CachedFields(rv.Type(), "json")
+	res := make([]string, len(titles))
+	for i := range res {
+		f, ok := fields.ByName(titles[i])
+		So(ok, ShouldBeTrue)
+		res[i], _ = reflectUtils.FormatInstanceToStr(rv.FieldByIndex(f.Index))
+	}
+	return res
+}
+
+func TestOrderCodec(t *testing.T) {
+	Convey("parse", t, func() {
+		source := newOrderFixture()
+		want := reflectOrders(source)
+		got, errs := codecOrders(source, OrderCodec{})
+		So(got, ShouldResemble, want)
+
+		So(got[0].Created, ShouldEqual, time.Date(2023, 8, 7, 0, 34, 0, 0, time.Local))
+		So(got[0].Payment.Pay(), ShouldEqual, "card 6222")
+		// json:"-" 的字段不对应任何列
+		So(got[0].Memo, ShouldEqual, "")
+		So(got[1].Timeout, ShouldEqual, 90*time.Second)
+		So(errs[0], ShouldBeNil)
+		So(errs[1], ShouldBeNil)
+		So(errs[3], ShouldBeNil)
+
+		fieldErrs, ok := errs[2].(reflectUtils.BindErrors)
+		So(ok, ShouldBeTrue)
+		paths := make([]string, 0, len(fieldErrs))
+		for _, e := range fieldErrs {
+			paths = append(paths, e.Field)
+		}
+		So(paths, ShouldResemble, []string{"Base.Id", "Count", "Paid", "Created", "Timeout", "Status", "Level", "Attrs", "Address", "Payment"})
+	})
+
+	Convey("parse opts", t, func() {
+		opts := []reflectUtils.ParseOpt{
+			reflectUtils.WithStrict(),
+			reflectUtils.WithConverter(reflect.TypeOf(""), func(s string) (interface{}, error) {
+				return strings.ToUpper(s), nil
+			}),
+		}
+		source := newOrderFixture()
+		want := reflectOrders(source, opts...)
+		got, errs := codecOrders(source, NewOrderCodec(opts...))
+		So(got, ShouldResemble, want)
+		So(got[0].Name, ShouldEqual, "JACK")
+		So(errs[2], ShouldNotBeNil)
+	})
+
+	Convey("format", t, func() {
+		codec := OrderCodec{}
+		orders, _ := codecOrders(newOrderFixture(), codec)
+		orders = append(orders, Order{Level: 3})
+		for i, o := range orders {
+			row := codec.FormatRow(o)
+			So(row, ShouldResemble, formatFields(o, codec.Titles()))
+			if o.Level == 3 {
+				So(row[8], ShouldEqual, "")
+			}
+			if i >= 2 {
+				// 只有前两行的所有字段都是合法的值
+				continue
+			}
+
+			back, err := codec.ParseRow(row, codec.Titles())
+			So(err, ShouldBeNil)
+			So(back, ShouldResemble, Order{Base: o.Base, Name: o.Name, Point: o.Point, Count: o.Count, Paid: o.Paid,
+				Created: o.Created, Timeout: o.Timeout, Status: o.Status, Level: o.Level,
+				Tags: o.Tags, Attrs: o.Attrs, Address: o.Address, Payment: o.Payment})
+		}
+
+		row := codec.WithFormat(reflectUtils.WithTimeLayout(time.RFC3339)).FormatRow(orders[0])
+		So(row[5], ShouldEqual, orders[0].Created.Format(time.RFC3339))
+	})
+
+	Convey("same columns as writer", t, func() {
+		codec := OrderCodec{}
+		orders, _ := codecOrders(newOrderFixture(), codec)
+		excelFile := filepath.Join(t.TempDir(), "order.xlsx")
+
+		w := excel.NewWriter(excel.WriterConfig{KeyFrom: excel.KeyFromTag, KeyTagName: "json"})
+		So(w.WriteStructs("Sheet1", orders), ShouldBeNil)
+		So(w.SaveAs(excelFile), ShouldBeNil)
+
+		// Writer 写入带类型的单元格, 只比较标题和文本列
+		sheet, err := excel.Xuri{}.GetSheet(excelFile, "Sheet1", excel.FirstRowAsTitles())
+		So(err, ShouldBeNil)
+		So(sheet.Titles(), ShouldResemble, codec.Titles())
+		So(len(sheet.Rows()), ShouldEqual, len(orders))
+		row := codec.FormatRow(orders[0])
+		So(sheet.Rows()[0][1], ShouldEqual, row[1])
+		So(sheet.Rows()[0][9:], ShouldResemble, row[9:])
+	})
+}
+
+func TestPositionCodec(t *testing.T) {
+	Convey("parse", t, func() {
+		source := excel.NewFixture().
+			Sheet("Sheet1").
+			Row("jack", "1|2", "3", "x").
+			Row("tom", "", "x").
+			Row("lucy").
+			Fixture().
+			Memory("order.xlsx")
+		r := excel.NewReader(excel.ReaderConfig{Source: source})
+		ret, err := r.Parse(position{}, "order.xlsx", "Sheet1")
+		So(err, ShouldBeNil)
+		want := ret.([]position)
+
+		sheet, err := source.GetSheet("order.xlsx", "Sheet1")
+		So(err, ShouldBeNil)
+		codec := newPositionCodec()
+		for i, row := range sheet.Rows() {
+			got, err := codec.ParseRow(row, sheet.Titles())
+			So(got, ShouldResemble, want[i])
+			So(err != nil, ShouldEqual, i == 1)
+		}
+		So(want[0], ShouldResemble, position{Name: "jack", Count: 3, Ids: []int{1, 2}})
+		So(codec.FormatRow(want[0]), ShouldResemble, []string{"jack", "[1,2]", "3"})
+	})
+}
+
+func BenchmarkParseRow(b *testing.B) {
+	titles := OrderCodec{}.Titles()
+	row := []string{"1", "jack", "17.23", "3", "yes", "2023-08-07 00:34:00", "1h30m", "1", "low", "a,b", "", "", ""}
+
+	b.Run("reflect", func(b *testing.B) {
+		fields := reflectUtils.CachedFields(reflect.TypeOf(Order{}), "json")
+		opts := reflectUtils.NewParseOptions()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v := reflect.New(fields.Type).Elem()
+			for col, s := range row {
+				f, _ := fields.ByName(titles[col])
+				nv, err := opts.ParseField(f, s)
+				if err != nil {
+					b.Fatal(err)
+				}
+				v.FieldByIndex(f.Index).Set(nv)
+			}
+		}
+	})
+
+	b.Run("generated", func(b *testing.B) {
+		codec := NewOrderCodec()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := codec.ParseRow(row, titles); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
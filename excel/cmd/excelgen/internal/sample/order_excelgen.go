@@ -0,0 +1,139 @@
+// Code generated by excelgen -type=Order; DO NOT EDIT.
+
+package sample
+
+import (
+	"github.com/JfL0unch/goUtil/excel"
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	"reflect"
+	"time"
+)
+
+// OrderCodec 不使用反射解析和格式化 Order 的行, 解析规则与 excel.Reader(KeyFrom=tag, KeyTagName=json) 相同;
+// 基本类型以外的字段仍通过 reflectUtils 解析和格式化. 零值使用默认选项
+type OrderCodec struct {
+	parse  *reflectUtils.ParseOptions
+	format *reflectUtils.FormatOptions
+}
+
+// NewOrderCodec opts 同 excel.ReaderConfig.ParseOpts
+func NewOrderCodec(opts ...reflectUtils.ParseOpt) OrderCodec {
+	return OrderCodec{parse: reflectUtils.NewParseOptions(opts...)}
+}
+
+// WithFormat FormatRow 使用的选项
+func (c OrderCodec) WithFormat(opts ...reflectUtils.FormatOpt) OrderCodec {
+	c.format = reflectUtils.NewFormatOptions(opts...)
+	return c
+}
+
+var orderCodecType = reflect.TypeOf(Order{})
+
+// Titles FormatRow 各列的标题, 与 excel.Writer(KeyFrom=tag, KeyTagName=json) 写入的标题行相同
+func (c OrderCodec) Titles() excel.Titles {
+	return excel.Titles{
+		0:  "id",
+		1:  "name",
+		2:  "point",
+		3:  "count",
+		4:  "paid",
+		5:  "created",
+		6:  "timeout",
+		7:  "status",
+		8:  "level",
+		9:  "tags",
+		10: "attrs",
+		11: "address",
+		12: "payment",
+	}
+}
+
+// ParseRow 按 titles 把一行解析为 Order, 没有对应字段的列被忽略;
+// 所有字段的错误以 reflectUtils.BindErrors 一起返回, 出错的字段为零值
+func (c OrderCodec) ParseRow(row []string, titles excel.Titles) (Order, error) {
+	var v Order
+	var errs reflectUtils.BindErrors
+	fields := reflectUtils.CachedFields(orderCodecType, "json")
+	for col, s := range row {
+		title, ok := titles[col]
+		if !ok {
+			continue
+		}
+		var path string
+		var err error
+		switch title {
+		case "id":
+			path = "Base.Id"
+			v.Base.Id, err = reflectUtils.ParseBasic[uint64](c.parse, s)
+		case "name":
+			path = "Name"
+			v.Name, err = reflectUtils.ParseBasic[string](c.parse, s)
+		case "point":
+			path = "Point"
+			v.Point, err = reflectUtils.ParseBasic[float64](c.parse, s)
+		case "count":
+			path = "Count"
+			v.Count, err = reflectUtils.ParseBasic[int32](c.parse, s)
+		case "paid":
+			path = "Paid"
+			v.Paid, err = reflectUtils.ParseBasic[bool](c.parse, s)
+		case "created":
+			path = "Created"
+			v.Created, err = reflectUtils.ParseBasic[time.Time](c.parse, s)
+		case "timeout":
+			path = "Timeout"
+			v.Timeout, err = reflectUtils.ParseBasic[time.Duration](c.parse, s)
+		case "status":
+			path = "Status"
+			f, _ := fields.ByPath(path)
+			v.Status, err = reflectUtils.ParseFieldAs[Status](c.parse, f, s)
+		case "level":
+			path = "Level"
+			f, _ := fields.ByPath(path)
+			v.Level, err = reflectUtils.ParseFieldAs[Level](c.parse, f, s)
+		case "tags":
+			path = "Tags"
+			f, _ := fields.ByPath(path)
+			v.Tags, err = reflectUtils.ParseFieldAs[[]string](c.parse, f, s)
+		case "attrs":
+			path = "Attrs"
+			f, _ := fields.ByPath(path)
+			v.Attrs, err = reflectUtils.ParseFieldAs[map[string]int](c.parse, f, s)
+		case "address":
+			path = "Address"
+			f, _ := fields.ByPath(path)
+			v.Address, err = reflectUtils.ParseFieldAs[*Address](c.parse, f, s)
+		case "payment":
+			path = "Payment"
+			f, _ := fields.ByPath(path)
+			v.Payment, err = reflectUtils.ParseFieldAs[PayMethod](c.parse, f, s)
+		}
+		if err != nil {
+			errs = append(errs, &reflectUtils.FieldError{Field: path, Key: titles[col], Value: s, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return v, errs
+	}
+	return v, nil
+}
+
+// FormatRow 把 v 格式化为一行, 列与 Titles 对应, 单元格同 reflectUtils.FormatInstanceToStr;
+// 格式化失败的字段为空字符串
+func (c OrderCodec) FormatRow(v Order) []string {
+	row := make([]string, 13)
+	row[0] = reflectUtils.FormatBasic(c.format, v.Base.Id)
+	row[1] = reflectUtils.FormatBasic(c.format, v.Name)
+	row[2] = reflectUtils.FormatBasic(c.format, v.Point)
+	row[3] = reflectUtils.FormatBasic(c.format, v.Count)
+	row[4] = reflectUtils.FormatBasic(c.format, v.Paid)
+	row[5] = reflectUtils.FormatBasic(c.format, v.Created)
+	row[6] = reflectUtils.FormatBasic(c.format, v.Timeout)
+	row[7], _ = reflectUtils.FormatAs(c.format, v.Status)
+	row[8], _ = reflectUtils.FormatAs(c.format, v.Level)
+	row[9], _ = reflectUtils.FormatAs(c.format, v.Tags)
+	row[10], _ = reflectUtils.FormatAs(c.format, v.Attrs)
+	row[11], _ = reflectUtils.FormatAs(c.format, v.Address)
+	row[12], _ = reflectUtils.FormatAs(c.format, v.Payment)
+	return row
+}
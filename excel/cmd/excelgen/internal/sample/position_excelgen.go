@@ -0,0 +1,79 @@
+// Code generated by excelgen -type=position -position; DO NOT EDIT.
+
+package sample
+
+import (
+	"github.com/JfL0unch/goUtil/excel"
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	"reflect"
+)
+
+// positionCodec 不使用反射解析和格式化 position 的行, 解析规则与 excel.Reader(SheetWithTitle=false, PositionTagName=excel) 相同;
+// 基本类型以外的字段仍通过 reflectUtils 解析和格式化. 零值使用默认选项
+type positionCodec struct {
+	parse  *reflectUtils.ParseOptions
+	format *reflectUtils.FormatOptions
+}
+
+// newPositionCodec opts 同 excel.ReaderConfig.ParseOpts
+func newPositionCodec(opts ...reflectUtils.ParseOpt) positionCodec {
+	return positionCodec{parse: reflectUtils.NewParseOptions(opts...)}
+}
+
+// WithFormat FormatRow 使用的选项
+func (c positionCodec) WithFormat(opts ...reflectUtils.FormatOpt) positionCodec {
+	c.format = reflectUtils.NewFormatOptions(opts...)
+	return c
+}
+
+var positionCodecType = reflect.TypeOf(position{})
+
+// Titles FormatRow 各列的标题
+func (c positionCodec) Titles() excel.Titles {
+	return excel.Titles{
+		0: "",
+		1: "",
+		2: "",
+	}
+}
+
+// ParseRow 按位置 tag 把一行解析为 position, 没有对应字段的列被忽略;
+// 所有字段的错误以 reflectUtils.BindErrors 一起返回, 出错的字段为零值
+func (c positionCodec) ParseRow(row []string, titles excel.Titles) (position, error) {
+	var v position
+	var errs reflectUtils.BindErrors
+	fields := reflectUtils.CachedFields(positionCodecType, "json")
+	for col, s := range row {
+		var path string
+		var err error
+		switch col {
+		case 0:
+			path = "Name"
+			v.Name, err = reflectUtils.ParseBasic[string](c.parse, s)
+		case 1:
+			path = "Ids"
+			f, _ := fields.ByPath(path)
+			v.Ids, err = reflectUtils.ParseFieldAs[[]int](c.parse, f, s)
+		case 2:
+			path = "Count"
+			v.Count, err = reflectUtils.ParseBasic[int](c.parse, s)
+		}
+		if err != nil {
+			errs = append(errs, &reflectUtils.FieldError{Field: path, Key: titles[col], Value: s, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return v, errs
+	}
+	return v, nil
+}
+
+// FormatRow 把 v 格式化为一行, 列与 Titles 对应, 单元格同 reflectUtils.FormatInstanceToStr;
+// 格式化失败的字段为空字符串
+func (c positionCodec) FormatRow(v position) []string {
+	row := make([]string, 3)
+	row[0] = reflectUtils.FormatBasic(c.format, v.Name)
+	row[1], _ = reflectUtils.FormatAs(c.format, v.Ids)
+	row[2] = reflectUtils.FormatBasic(c.format, v.Count)
+	return row
+}
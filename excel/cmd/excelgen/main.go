@@ -0,0 +1,63 @@
+// excelgen 为结构体生成不使用反射的行解析和格式化代码(ParseRow/FormatRow),
+// 规则与 excel.Reader/excel.Writer 相同. 在结构体所在的文件中:
+//
+//	//go:generate go run github.com/JfL0unch/goUtil/excel/cmd/excelgen -type=Order -tag=json
+//
+// 生成 order_excelgen.go, 包含 OrderCodec:
+//
+//	codec := NewOrderCodec(reflectUtils.WithStrict())
+//	order, err := codec.ParseRow(row, titles)
+//	row = codec.FormatRow(order)
+//
+// string/bool/数字/time.Time/time.Duration 字段直接解析, 其余字段(别名类型、结构体、slice 等)
+// 仍通过 reflectUtils 解析, 结果与反射一致.
+//
+// 参数:
+//
+//	-type          结构体类型名, 必填
+//	-key           列名来源 tag 或 field_name, 同 excel.ReaderConfig.KeyFrom, 默认 tag
+//	-tag           同 excel.ReaderConfig.KeyTagName, 默认 json
+//	-position      按位置 tag 对应列, 同 excel.ReaderConfig.SheetWithTitle=false
+//	-position-tag  同 excel.ReaderConfig.PositionTagName, 默认 excel
+//	-output        输出文件, 默认 <type>_excelgen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	c := Config{}
+	flag.StringVar(&c.Type, "type", "", "struct type name")
+	flag.StringVar(&c.KeyFrom, "key", keyFromTag, "column names from tag or field_name")
+	flag.StringVar(&c.Tag, "tag", "", "tag of column names, default json")
+	flag.BoolVar(&c.Position, "position", false, "map columns by position tag")
+	flag.StringVar(&c.PositionTag, "position-tag", "", "position tag name, default excel")
+	output := flag.String("output", "", "output file, default <type>_excelgen.go")
+	flag.Parse()
+
+	if c.Type == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	c.Dir = "."
+	if len(flag.Args()) > 0 {
+		c.Dir = flag.Arg(0)
+	}
+
+	src, err := Generate(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "excelgen:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		*output = filepath.Join(c.Dir, OutputName(c.Type))
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "excelgen:", err)
+		os.Exit(1)
+	}
+}
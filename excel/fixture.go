@@ -0,0 +1,127 @@
+package excel
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"os"
+)
+
+// Fixture 测试用的 excel 内容构造器, 例如:
+//
+//	f := excel.NewFixture().
+//		Sheet("Sheet1").Header("id", "name").Row("1", "jack").
+//		Sheet("Sheet2").Row("a", "b").
+//		Fixture()
+//
+// 可以通过 Memory 直接读取, 也可以用 TempFile 生成真实的 .xlsx 文件.
+type Fixture struct {
+	sheets []*FixtureSheet
+}
+
+type FixtureSheet struct {
+	fixture *Fixture
+	name    string
+	header  []string
+	rows    [][]string
+}
+
+func NewFixture() *Fixture {
+	return &Fixture{}
+}
+
+// Sheet 返回名为 name 的 sheet, 不存在时按调用顺序新建
+func (f *Fixture) Sheet(name string) *FixtureSheet {
+	if s := f.find(name); s != nil {
+		return s
+	}
+	s := &FixtureSheet{fixture: f, name: name}
+	f.sheets = append(f.sheets, s)
+	return s
+}
+
+func (f *Fixture) find(name string) *FixtureSheet {
+	for _, s := range f.sheets {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Memory 以 excelFile 为名注册到新的 Memory
+func (f *Fixture) Memory(excelFile string) *Memory {
+	return NewMemory().Add(excelFile, f)
+}
+
+// Save 写为 .xlsx 文件, sheet 顺序与添加顺序一致
+func (f *Fixture) Save(fileName string) error {
+	w := NewWriter(WriterConfig{})
+	for _, s := range f.sheets {
+		if err := w.ensureSheet(s.name); err != nil {
+			return err
+		}
+		for i, row := range s.rawRows() {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			row := row
+			if err := w.file.SetSheetRow(s.name, cell, &row); err != nil {
+				return errors.Wrapf(err, "SetSheetRow(%s,%s)", s.name, cell)
+			}
+		}
+	}
+	return w.SaveAs(fileName)
+}
+
+// TempFile 在 dir 下生成临时 .xlsx 文件并返回路径, 参数同 os.CreateTemp,
+// 由调用方负责删除
+func (f *Fixture) TempFile(dir, pattern string) (string, error) {
+	if pattern == "" {
+		pattern = "fixture-*.xlsx"
+	}
+	tmp, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", errors.Wrap(err, "CreateTemp")
+	}
+	name := tmp.Name()
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := f.Save(name); err != nil {
+		_ = os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// Header 设置标题行(第一行)
+func (s *FixtureSheet) Header(titles ...string) *FixtureSheet {
+	s.header = titles
+	return s
+}
+
+// Row 追加一行数据
+func (s *FixtureSheet) Row(cells ...string) *FixtureSheet {
+	s.rows = append(s.rows, cells)
+	return s
+}
+
+// Sheet 切换到(或新建)另一个 sheet, 便于链式调用
+func (s *FixtureSheet) Sheet(name string) *FixtureSheet {
+	return s.fixture.Sheet(name)
+}
+
+func (s *FixtureSheet) Fixture() *Fixture {
+	return s.fixture
+}
+
+// rawRows 标题行(若有) + 数据行
+func (s *FixtureSheet) rawRows() [][]string {
+	res := make([][]string, 0, len(s.rows)+1)
+	if s.header != nil {
+		res = append(res, s.header)
+	}
+	return append(res, s.rows...)
+}
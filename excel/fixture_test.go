@@ -0,0 +1,189 @@
+package excel
+
+import (
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTypXFixture() *Fixture {
+	return NewFixture().
+		Sheet("Sheet1").
+		Header("id", "name", "point", "time", "status").
+		Row("1", "jack", "17.23", "2023-08-07 00:34:00", "1").
+		Row("2", "tom", "0.58", "2023/8/7 0:34:51", "1").
+		Row("3", "lucy", "-3", "", "2").
+		Sheet("Sheet2").
+		Row("x", "y").
+		Fixture()
+}
+
+// sheetOnly 只实现 Intf 的 source
+type sheetOnly struct {
+	Intf
+}
+
+func TestMemory(t *testing.T) {
+	Convey("memory", t, func() {
+		var source Intf = newTypXFixture().Memory("mem.xlsx")
+		config := ReaderConfig{
+			SheetWithTitle: true,
+			KeyFrom:        KeyFromTag,
+			KeyTagName:     "json",
+			Source:         source,
+		}
+
+		Convey("parse", func() {
+			p := NewReader(config)
+			retI, err := p.Parse(typX{}, "mem.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typX)
+			So(len(ret), ShouldEqual, 3)
+			So(ret[0].Name, ShouldEqual, "jack")
+			So(ret[1].Point, ShouldEqual, 0.58)
+			So(ret[2].Status, ShouldEqual, 2)
+		})
+
+		Convey("range and count", func() {
+			p := NewReader(config)
+			retI, err := p.ParseRange(typX{}, "mem.xlsx", "Sheet1", 1, 1)
+			So(err, ShouldBeNil)
+			So(retI.([]typX)[0].Name, ShouldEqual, "tom")
+
+			n, err := p.CountRows("mem.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+
+			// 没有实现 RowCounter 时读取整个 sheet 计数
+			c := config
+			c.Source = sheetOnly{source}
+			p = NewReader(c)
+			n, err = p.CountRows("mem.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+		})
+
+		Convey("parse opts", func() {
+			c := config
+			c.ParseOpts = []reflectUtils.ParseOpt{
+				reflectUtils.WithConverter(reflect.TypeOf(""), func(s string) (interface{}, error) {
+					return strings.ToUpper(s), nil
+				}),
+			}
+			p := NewReader(c)
+			retI, err := p.Parse(typX{}, "mem.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			So(retI.([]typX)[0].Name, ShouldEqual, "JACK")
+		})
+
+		Convey("defaults", func() {
+			type withDefault struct {
+				Id     uint64 `json:"id"`
+				Time   string `json:"time" default:"unknown"`
+				Status Status `json:"status" default:"9"`
+				Memo   string `json:"memo" default:"none"`
+			}
+			c := config
+			c.Defaults = true
+			p := NewReader(c)
+			retI, err := p.Parse(withDefault{}, "mem.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			ret := retI.([]withDefault)
+			So(ret[0].Time, ShouldEqual, "2023-08-07 00:34:00")
+			So(ret[2].Time, ShouldEqual, "unknown")
+			So(ret[2].Status, ShouldEqual, 2)
+			So(ret[2].Memo, ShouldEqual, "none")
+
+			p = NewReader(config)
+			retI, err = p.Parse(withDefault{}, "mem.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			So(retI.([]withDefault)[2].Time, ShouldEqual, "")
+
+			// 值为 0 的单元格不使用默认值
+			source := NewFixture().Sheet("Sheet1").
+				Header("id", "status").
+				Row("1", "0").
+				Row("2", "").
+				Fixture().
+				Memory("zero.xlsx")
+			c.Source = source
+			p = NewReader(c)
+			retI, err = p.Parse(withDefault{}, "zero.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			ret = retI.([]withDefault)
+			So(ret[0].Status, ShouldEqual, 0)
+			So(ret[1].Status, ShouldEqual, 9)
+			So(ret[0].Time, ShouldEqual, "unknown")
+		})
+
+		Convey("not exist", func() {
+			_, err := source.GetSheet("other.xlsx", "Sheet1")
+			So(err, ShouldNotBeNil)
+			_, err = source.GetSheet("mem.xlsx", "Sheet3")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("same as xuri", func() {
+			fileName, err := newTypXFixture().TempFile(t.TempDir(), "")
+			So(err, ShouldBeNil)
+			_, err = os.Stat(fileName)
+			So(err, ShouldBeNil)
+
+			for _, sheetName := range []string{"Sheet1", "Sheet2"} {
+				for _, opts := range [][]Opt{
+					{FirstRowAsTitles()},
+					{},
+					{FirstRowAsTitles(), RowOffset(1), RowLimit(1)},
+					{RowOffset(2)},
+				} {
+					want, err := Xuri{}.GetSheet(fileName, sheetName, opts...)
+					So(err, ShouldBeNil)
+					got, err := source.GetSheet("mem.xlsx", sheetName, opts...)
+					So(err, ShouldBeNil)
+					So(got, ShouldResemble, want)
+
+					wantN, err := Xuri{}.CountRows(fileName, sheetName, opts...)
+					So(err, ShouldBeNil)
+					gotN, err := source.(RowCounter).CountRows("mem.xlsx", sheetName, opts...)
+					So(err, ShouldBeNil)
+					So(gotN, ShouldEqual, wantN)
+				}
+			}
+		})
+
+		Convey("empty cells same as xuri", func() {
+			f := NewFixture().Sheet("Sheet1").
+				Header("a", "b", "", "").
+				Row("1", "", "").
+				Row("", "", "").
+				Row("2", "", "3", "").
+				Row("", "").
+				Fixture()
+			fileName, err := f.TempFile(t.TempDir(), "")
+			So(err, ShouldBeNil)
+			mem := f.Memory("mem.xlsx")
+
+			for _, opts := range [][]Opt{{FirstRowAsTitles()}, {}, {RowOffset(1)}} {
+				want, err := Xuri{}.GetSheet(fileName, "Sheet1", opts...)
+				So(err, ShouldBeNil)
+				got, err := mem.GetSheet("mem.xlsx", "Sheet1", opts...)
+				So(err, ShouldBeNil)
+				So(got, ShouldResemble, want)
+
+				wantN, err := Xuri{}.CountRows(fileName, "Sheet1", opts...)
+				So(err, ShouldBeNil)
+				gotN, err := mem.CountRows("mem.xlsx", "Sheet1", opts...)
+				So(err, ShouldBeNil)
+				So(gotN, ShouldEqual, wantN)
+			}
+			sheet, err := mem.GetSheet("mem.xlsx", "Sheet1", FirstRowAsTitles())
+			So(err, ShouldBeNil)
+			So(sheet.Titles(), ShouldResemble, Titles{0: "a", 1: "b"})
+			So(sheet.Rows(), ShouldResemble, [][]string{{"1"}, nil, {"2", "", "3"}})
+		})
+	})
+}
@@ -3,3 +3,9 @@ package excel
 type Intf interface {
 	GetSheet(excelFile, sheetName string, opts ...Opt) (*Sheet, error)
 }
+
+// RowCounter 可以不读取数据、只统计行数的 Intf 实现
+type RowCounter interface {
+	// CountRows 数据行数(不含标题行)
+	CountRows(excelFile, sheetName string, opts ...Opt) (int, error)
+}
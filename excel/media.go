@@ -0,0 +1,208 @@
+package excel
+
+import (
+	"context"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"strings"
+)
+
+// Image 单元格中的图片, 作为结构体字段类型时读取/写入锚定在该单元格的图片
+type Image struct {
+	// Format 扩展名, 如 .png .jpg
+	Format  string
+	Data    []byte
+	AltText string
+}
+
+// Link 带超链接的单元格, 作为结构体字段类型时读取/写入单元格文本及链接.
+// Target 为外部地址(如 https://...), 或工作簿内的位置(如 Sheet1!A1)
+type Link struct {
+	Text   string
+	Target string
+}
+
+var (
+	imageType = reflect.TypeOf(Image{})
+	linkType  = reflect.TypeOf(Link{})
+)
+
+func isMediaType(t reflect.Type) bool {
+	return t == imageType || t == linkType
+}
+
+type cellKey struct {
+	row int
+	col int
+}
+
+// MediaSelector 根据标题行选择需要读取图片/超链接的列(下标从0开始)
+type MediaSelector func(titles Titles) []int
+
+// WithMedia 读取所选列中的图片和超链接, 通过 Sheet.Images/Sheet.Link 获取
+func WithMedia(selector MediaSelector) Opt {
+	return func(p *Parser) {
+		p.media = selector
+	}
+}
+
+// Images 数据行 row、列 col 处锚定的图片, 下标均从0开始
+func (e Sheet) Images(row, col int) []Image {
+	return e.images[cellKey{row: row, col: col}]
+}
+
+// Link 数据行 row、列 col 处的超链接, 下标均从0开始
+func (e Sheet) Link(row, col int) (Link, bool) {
+	target, ok := e.links[cellKey{row: row, col: col}]
+	if !ok {
+		return Link{}, false
+	}
+	text := ""
+	if row < len(e.rows) && col < len(e.rows[row]) {
+		text = e.rows[row][col]
+	}
+	return Link{Text: text, Target: target}, true
+}
+
+// readMedia 读取所选列的图片和超链接,
+// firstRow 为第一行数据行在 sheet 中的行号(从1开始).
+// 图片先由 GetPictureCells 得到 sheet 中所有锚定了图片的单元格, 只读取其中落在所选列的;
+// excelize 没有列出 sheet 超链接的接口, 超链接逐个查询所选列的单元格
+func (x Xuri) readMedia(ctx context.Context, f *excelize.File, sheetName string, sheet *Sheet, columns []int, firstRow int) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	sheet.images = make(map[cellKey][]Image, 0)
+	sheet.links = make(map[cellKey]string, 0)
+
+	selected := make(map[int]bool, len(columns))
+	for _, col := range columns {
+		selected[col] = true
+	}
+	picCells, err := f.GetPictureCells(sheetName)
+	if err != nil {
+		return err
+	}
+	for _, cell := range picCells {
+		col, row, err := excelize.CellNameToCoordinates(cell)
+		if err != nil {
+			return err
+		}
+		key := cellKey{row: row - firstRow, col: col - 1}
+		if !selected[key.col] || key.row < 0 || key.row >= len(sheet.rows) {
+			continue
+		}
+		pics, err := f.GetPictures(sheetName, cell)
+		if err != nil {
+			return err
+		}
+		for _, pic := range pics {
+			img := Image{Format: pic.Extension, Data: pic.File}
+			if pic.Format != nil {
+				img.AltText = pic.Format.AltText
+			}
+			sheet.images[key] = append(sheet.images[key], img)
+		}
+	}
+
+	for row := range sheet.rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, col := range columns {
+			cell, err := excelize.CoordinatesToCellName(col+1, firstRow+row)
+			if err != nil {
+				return err
+			}
+			ok, target, err := f.GetCellHyperLink(sheetName, cell)
+			if err != nil {
+				return err
+			}
+			if ok {
+				sheet.links[cellKey{row: row, col: col}] = target
+			}
+		}
+	}
+	return nil
+}
+
+// mediaSelector 返回 Image/Link 类型字段对应的列
+func (r *Reader) mediaSelector() MediaSelector {
+	if r.config.SheetWithTitle {
+		return func(titles Titles) []int {
+			res := make([]int, 0)
+			for i, title := range titles {
+				if field, exist := r.structFieldMap[title]; exist && isMediaType(field.Type) {
+					res = append(res, i)
+				}
+			}
+			return res
+		}
+	}
+
+	structTyp := reflect.TypeOf(r.structTmpl)
+	return func(_ Titles) []int {
+		indexMap, err := getStructIndexMap(structTyp, r.config.PositionTagName)
+		if err != nil {
+			return nil
+		}
+		res := make([]int, 0)
+		for col, index := range indexMap {
+			if isMediaType(structTyp.FieldByIndex(index).Type) {
+				res = append(res, col)
+			}
+		}
+		return res
+	}
+}
+
+// hasMediaField 结构体是否包含 Image/Link 类型的字段
+func hasMediaField(structTyp reflect.Type) bool {
+	for i := 0; i < structTyp.NumField(); i++ {
+		ft := structTyp.Field(i)
+		if isMediaType(ft.Type) {
+			return true
+		}
+		if ft.Type.Kind() == reflect.Struct && ft.Anonymous && hasMediaField(ft.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// setMedia 把数据行 rowIndex 中的图片/超链接写入 Image/Link 类型的字段,
+// Image 取锚定在单元格的第一张图片, Link 没有超链接时只有 Text
+func (r *Reader) setMedia(structToUpdate reflect.Value, rowIndex int) {
+	sheet := r.sheet
+	for _, col := range r.mediaCols {
+		field, ok := r.columnField(structToUpdate, col)
+		if !ok {
+			continue
+		}
+		switch field.Type() {
+		case imageType:
+			if imgs := sheet.Images(rowIndex, col); len(imgs) > 0 {
+				field.Set(reflect.ValueOf(imgs[0]))
+			}
+		case linkType:
+			link, ok := sheet.Link(rowIndex, col)
+			if !ok && rowIndex < len(sheet.rows) && col < len(sheet.rows[rowIndex]) {
+				link = Link{Text: sheet.rows[rowIndex][col]}
+			}
+			field.Set(reflect.ValueOf(link))
+		}
+	}
+}
+
+// columnField 列 col 对应的字段
+func (r *Reader) columnField(structToUpdate reflect.Value, col int) (reflect.Value, bool) {
+	if col >= len(r.columnFields) || r.columnFields[col] == nil {
+		return reflect.Value{}, false
+	}
+	return structToUpdate.Elem().FieldByIndex(r.columnFields[col].Index), true
+}
+
+// isLocation 工作簿内的位置, 如 Sheet1!A1
+func isLocation(target string) bool {
+	return !strings.Contains(target, "://") && !strings.HasPrefix(target, "mailto:") && strings.Contains(target, "!")
+}
@@ -0,0 +1,93 @@
+package excel
+
+import (
+	"bytes"
+	. "github.com/smartystreets/goconvey/convey"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+type typProduct struct {
+	Id    uint64 `json:"id" excel:"idx=0"`
+	Name  string `json:"name" excel:"idx=1"`
+	Photo Image  `json:"photo" excel:"idx=2"`
+	Page  Link   `json:"page" excel:"idx=3"`
+}
+
+func pngBytes() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	buf := bytes.Buffer{}
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestMedia(t *testing.T) {
+	Convey("image and link", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "products.xlsx")
+		data := pngBytes()
+
+		products := []typProduct{
+			{Id: 1, Name: "cup", Photo: Image{Format: ".png", Data: data, AltText: "cup"}, Page: Link{Text: "cup page", Target: "https://example.com/cup"}},
+			{Id: 2, Name: "pen", Page: Link{Text: "see cup", Target: "products!B2"}},
+			{Id: 3, Name: "box", Page: Link{Text: "no link"}},
+		}
+
+		w := NewWriter(WriterConfig{KeyFrom: KeyFromTag, KeyTagName: "json"})
+		So(w.WriteStructs("products", products), ShouldBeNil)
+		So(w.SaveAs(excelFile), ShouldBeNil)
+
+		Convey("with title", func() {
+			p := NewReader(ReaderConfig{SheetWithTitle: true, KeyFrom: KeyFromTag, KeyTagName: "json"})
+			retI, err := p.Parse(typProduct{}, excelFile, "products")
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typProduct)
+			So(len(ret), ShouldEqual, 3)
+			So(ret[0].Name, ShouldEqual, "cup")
+			So(ret[0].Photo.Format, ShouldEqual, ".png")
+			So(ret[0].Photo.Data, ShouldResemble, data)
+			So(ret[0].Photo.AltText, ShouldEqual, "cup")
+			So(ret[0].Page, ShouldResemble, products[0].Page)
+			So(ret[1].Photo, ShouldResemble, Image{})
+			So(ret[1].Page, ShouldResemble, products[1].Page)
+			So(ret[2].Page, ShouldResemble, products[2].Page)
+		})
+
+		Convey("without title", func() {
+			p := NewReader(ReaderConfig{SheetWithTitle: false})
+			retI, err := p.ParseRange(typProduct{}, excelFile, "products", 1, 0)
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typProduct)
+			So(len(ret), ShouldEqual, 3)
+			So(ret[0].Photo.Data, ShouldResemble, data)
+			So(ret[0].Page.Target, ShouldEqual, "https://example.com/cup")
+		})
+
+		Convey("memory source", func() {
+			source := NewFixture().Sheet("products").
+				Header("id", "name", "photo", "page").
+				Row("1", "cup", "", "cup page").
+				Fixture().Memory("mem.xlsx")
+			p := NewReader(ReaderConfig{SheetWithTitle: true, KeyFrom: KeyFromTag, KeyTagName: "json", Source: source})
+			retI, err := p.Parse(typProduct{}, "mem.xlsx", "products")
+			So(err, ShouldBeNil)
+			So(retI.([]typProduct)[0].Page, ShouldResemble, Link{Text: "cup page"})
+		})
+
+		Convey("pictures outside the range", func() {
+			p := NewReader(ReaderConfig{SheetWithTitle: false})
+			retI, err := p.ParseRange(typProduct{}, excelFile, "products", 2, 0)
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typProduct)
+			So(len(ret), ShouldEqual, 2)
+			So(ret[0].Photo, ShouldResemble, Image{})
+			So(ret[0].Page.Target, ShouldEqual, "products!B2")
+		})
+	})
+}
@@ -0,0 +1,174 @@
+package excel
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"os"
+)
+
+// Memory 内存中的 Intf 实现, 用于测试.
+// 标题行、行窗口、ctx 取消、进度回调以及去掉行末和末尾的空单元格/空行与 Xuri 一致;
+// Fixture 没有图片和超链接, WithMedia 不起作用, Sheet.Images/Sheet.Link 总是为空.
+type Memory struct {
+	files map[string]*Fixture
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		files: make(map[string]*Fixture, 0),
+	}
+}
+
+// Add 注册文件, excelFile 只作为名字使用
+func (m *Memory) Add(excelFile string, f *Fixture) *Memory {
+	m.files[excelFile] = f
+	return m
+}
+
+func (m *Memory) GetSheet(excelFile, sheetName string, opts ...Opt) (*Sheet, error) {
+	parser := &Parser{}
+
+	for _, opt := range opts {
+		opt(parser)
+	}
+
+	raw, err := m.rawRows(excelFile, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	return sheetFromRaw(trimEmptyCells(raw), *parser)
+}
+
+func (m *Memory) CountRows(excelFile, sheetName string, opts ...Opt) (int, error) {
+	parser := &Parser{}
+
+	for _, opt := range opts {
+		opt(parser)
+	}
+
+	raw, err := m.rawRows(excelFile, sheetName)
+	if err != nil {
+		return 0, err
+	}
+	return countRaw(raw, *parser)
+}
+
+func (m *Memory) file(excelFile string) (*Fixture, error) {
+	f, exist := m.files[excelFile]
+	if !exist {
+		return nil, errors.Wrapf(os.ErrNotExist, "open %s", excelFile)
+	}
+	return f, nil
+}
+
+func (m *Memory) rawRows(excelFile, sheetName string) ([][]string, error) {
+	f, err := m.file(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	s := f.find(sheetName)
+	if s == nil {
+		return nil, excelize.ErrSheetNotExist{SheetName: sheetName}
+	}
+	return s.rawRows(), nil
+}
+
+// sheetFromRaw 由 sheet 的全部行(含标题行)生成 Sheet, 行为同 Xuri.GetSheet
+func sheetFromRaw(raw [][]string, parser Parser) (*Sheet, error) {
+	excelDatas, err := windowRows(raw, parser)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(excelDatas) <= 0 {
+		return nil, nil
+	}
+
+	excel := Sheet{offset: parser.offset}
+	titles, err := parser.GetTitles(excelDatas)
+	if err != nil {
+		return nil, err
+	}
+	excel.titles = titles
+
+	rows, err := parser.GetRows(excelDatas)
+	if err != nil {
+		return nil, err
+	}
+	excel.rows = rows
+
+	return &excel, nil
+}
+
+// countRaw 同 Xuri.CountRows, 只有空单元格的行也计入
+func countRaw(raw [][]string, parser Parser) (int, error) {
+	if err := parser.context().Err(); err != nil {
+		return 0, err
+	}
+
+	n := len(trimEmptyTail(raw))
+	if parser.WithTitle() && n > 0 {
+		n--
+	}
+	return n, nil
+}
+
+// windowRows 同 Xuri.readRows, 只保留标题行(若有)以及窗口内的数据行
+func windowRows(raw [][]string, parser Parser) ([][]string, error) {
+	ctx := parser.context()
+	results := make([][]string, 0, len(raw))
+
+	data := raw
+	if parser.WithTitle() && len(raw) > 0 {
+		results = append(results, raw[0])
+		data = raw[1:]
+	}
+
+	if parser.offset < len(data) {
+		data = data[parser.offset:]
+	} else {
+		data = nil
+	}
+	if parser.limit > 0 && parser.limit < len(data) {
+		data = data[:parser.limit]
+	}
+	data = trimEmptyTail(data)
+
+	progress := Progress{TotalRows: len(data)}
+	for _, row := range data {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+
+		progress.Rows++
+		parser.report(progress)
+	}
+	return trimEmptyTail(results), nil
+}
+
+// trimEmptyCells 去掉每行末尾的空单元格, 空行为 nil, 与 excelize.GetRows 一致; 不修改 raw
+func trimEmptyCells(raw [][]string) [][]string {
+	res := make([][]string, len(raw))
+	for i, row := range raw {
+		n := len(row)
+		for n > 0 && row[n-1] == "" {
+			n--
+		}
+		if n > 0 {
+			res[i] = row[:n:n]
+		}
+	}
+	return res
+}
+
+// trimEmptyTail 去掉末尾的空行, 与 excelize.GetRows 一致
+func trimEmptyTail(rows [][]string) [][]string {
+	max := 0
+	for i, row := range rows {
+		if len(row) > 0 {
+			max = i + 1
+		}
+	}
+	return rows[:max]
+}
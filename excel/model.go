@@ -1,9 +1,8 @@
 package excel
 
 import (
-	"fmt"
+	"context"
 	"github.com/pkg/errors"
-	"github.com/xuri/excelize/v2"
 	"reflect"
 )
 
@@ -29,6 +28,12 @@ type filter func(e Sheet) Sheet
 type Sheet struct {
 	titles Titles
 	rows   [][]string
+	// offset rows[0] 在 sheet 全部数据行(不含标题行)中的下标
+	offset int
+
+	// 通过 WithMedia 读取的图片和超链接
+	images map[cellKey][]Image
+	links  map[cellKey]string
 }
 
 func (e Sheet) Filter(fs filter) Sheet {
@@ -41,34 +46,56 @@ func (e Sheet) Rows() [][]string {
 func (e Sheet) Titles() Titles {
 	return e.titles
 }
+
+// ColumnCount 列数, 取标题行与各数据行中的最大值
+func (e Sheet) ColumnCount() int {
+	n := len(e.titles)
+	for _, row := range e.rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	return n
+}
+
+// Offset 第一行数据在整个 sheet 数据行(不含标题行)中的下标,
+// 用于分页读取后断点续读
+func (e Sheet) Offset() int {
+	return e.offset
+}
 func (e Sheet) Save(fileName string) error {
-	excel := excelize.NewFile()
+	return e.SaveContext(context.Background(), fileName, nil)
+}
+
+// SaveContext 同 Save, ctx 取消时停止写入并返回 ctx.Err(),
+// progress 每写入一行回调, 可为空
+func (e Sheet) SaveContext(ctx context.Context, fileName string, progress ProgressFunc) error {
+	w := NewWriter(WriterConfig{Progress: progress})
 
 	sheetName := "sheet1"
-	err := excel.SetSheetName("Sheet1", sheetName)
-	if err != nil {
-		return errors.Wrapf(err, "excel.SetSheetName('Sheet1',%s)", sheetName)
+	if err := w.WriteSheetContext(ctx, sheetName, e); err != nil {
+		return errors.Wrapf(err, "WriteSheet(%s)", sheetName)
 	}
 
-	titles := e.Titles()
-	err = excel.SetSheetRow(sheetName, fmt.Sprintf("A%d", 1), &titles)
-	if err != nil {
-		return errors.Wrapf(err, "excel.SetSheetRow(%s,A1)", sheetName)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	for i, row := range e.Rows() {
-		cid := i + 2
-		err = excel.SetSheetRow(sheetName, fmt.Sprintf("A%d", cid), &row)
-		if err != nil {
-			fmt.Printf("excel.SetSheetRow(%s,A%d) err:%s", sheetName, cid, err)
+	return w.SaveAs(fileName)
+}
+
+// titleRow 按列下标排列的标题行
+func (e Sheet) titleRow() []string {
+	n := -1
+	for i := range e.titles {
+		if i > n {
+			n = i
 		}
 	}
-
-	excel.SetActiveSheet(0)
-
-	// Save spreadsheet by the given path.
-	if err := excel.SaveAs(fileName); err != nil {
-		return err
+	res := make([]string, n+1)
+	for i, t := range e.titles {
+		if i >= 0 {
+			res[i] = t
+		}
 	}
-
-	return nil
+	return res
 }
@@ -0,0 +1,549 @@
+package excel
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+	nsOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	nsTable  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	nsStyle  = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+)
+
+// Ods OpenDocument spreadsheet(.ods) 的 Intf 实现.
+// 单元格取显示文本(text:p), 合并单元格中被覆盖的部分为空字符串;
+// 不支持图片和超链接读取.
+type Ods struct{}
+
+func (o Ods) GetSheet(excelFile, sheetName string, opts ...Opt) (*Sheet, error) {
+	parser := &Parser{}
+
+	for _, opt := range opts {
+		opt(parser)
+	}
+
+	raw, err := o.readTable(excelFile, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	return sheetFromRaw(raw, *parser)
+}
+
+func (o Ods) CountRows(excelFile, sheetName string, opts ...Opt) (int, error) {
+	parser := &Parser{}
+
+	for _, opt := range opts {
+		opt(parser)
+	}
+
+	raw, err := o.readTable(excelFile, sheetName)
+	if err != nil {
+		return 0, err
+	}
+	return countRaw(raw, *parser)
+}
+
+// readTable 读取 content.xml 中名为 sheetName 的 table:table 的全部行
+func (o Ods) readTable(excelFile, sheetName string) ([][]string, error) {
+	zr, err := zip.OpenReader(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	rc, err := o.openPart(zr, excelFile, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return readOdsTable(rc, sheetName)
+}
+
+func (o Ods) openPart(zr *zip.ReadCloser, excelFile, name string) (io.ReadCloser, error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, errors.Wrapf(os.ErrNotExist, "%s: %s", excelFile, name)
+}
+
+func readOdsTable(r io.Reader, sheetName string) ([][]string, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, excelize.ErrSheetNotExist{SheetName: sheetName}
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "content.xml")
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Space != nsTable || start.Name.Local != "table" {
+			continue
+		}
+		if odsAttr(start, nsTable, "name") != sheetName {
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return readOdsRows(dec)
+	}
+}
+
+// readOdsRows 读取当前 table 的行, 直到 </table:table>.
+// 重复的空行(number-rows-repeated)只有在其后还有非空行时才展开.
+func readOdsRows(dec *xml.Decoder) ([][]string, error) {
+	rows := make([][]string, 0, 64)
+	pendingEmpty := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "content.xml")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != nsTable {
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			switch t.Name.Local {
+			case "table-row":
+				cells, repeat, err := readOdsRow(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				if len(cells) == 0 {
+					pendingEmpty += repeat
+					continue
+				}
+				for ; pendingEmpty > 0; pendingEmpty-- {
+					rows = append(rows, []string{})
+				}
+				for i := 0; i < repeat; i++ {
+					rows = append(rows, cells)
+				}
+			case "table-header-rows", "table-rows", "table-row-group":
+				// 容器元素, 继续读取其中的行
+			default:
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space == nsTable && t.Name.Local == "table" {
+				return rows, nil
+			}
+		}
+	}
+}
+
+// readOdsRow 读取一行, 末尾的空单元格被去掉
+func readOdsRow(dec *xml.Decoder, start xml.StartElement) ([]string, int, error) {
+	repeat := odsRepeat(start, "number-rows-repeated")
+	cells := make([]string, 0)
+	pendingEmpty := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "content.xml")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != nsTable || (t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell") {
+				if err := dec.Skip(); err != nil {
+					return nil, 0, err
+				}
+				continue
+			}
+			text, err := readOdsCell(dec, t)
+			if err != nil {
+				return nil, 0, err
+			}
+			cellRepeat := odsRepeat(t, "number-columns-repeated")
+			if text == "" {
+				pendingEmpty += cellRepeat
+				continue
+			}
+			for ; pendingEmpty > 0; pendingEmpty-- {
+				cells = append(cells, "")
+			}
+			for i := 0; i < cellRepeat; i++ {
+				cells = append(cells, text)
+			}
+		case xml.EndElement:
+			return cells, repeat, nil
+		}
+	}
+}
+
+// readOdsCell 单元格的显示文本, 多个段落以换行连接;
+// 没有文本时取 office:value 等属性值
+func readOdsCell(dec *xml.Decoder, start xml.StartElement) (string, error) {
+	buf := strings.Builder{}
+	paragraphs := 0
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return "", errors.Wrap(err, "content.xml")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space == nsOffice && t.Name.Local == "annotation" {
+				if err := dec.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			depth++
+			if t.Name.Space != nsText {
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				if depth == 1 {
+					if paragraphs > 0 {
+						buf.WriteString("\n")
+					}
+					paragraphs++
+				}
+			case "s":
+				n, err := strconv.Atoi(odsAttr(t, nsText, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				buf.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				buf.WriteString("\t")
+			case "line-break":
+				buf.WriteString("\n")
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				if buf.Len() == 0 {
+					return odsValueAttr(start), nil
+				}
+				return buf.String(), nil
+			}
+			depth--
+		case xml.CharData:
+			if depth > 0 {
+				buf.Write(t)
+			}
+		}
+	}
+}
+
+func odsValueAttr(start xml.StartElement) string {
+	for _, name := range []string{"value", "date-value", "time-value", "boolean-value", "string-value"} {
+		if v := odsAttr(start, nsOffice, name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func odsAttr(start xml.StartElement, space, local string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func odsRepeat(start xml.StartElement, local string) int {
+	n, err := strconv.Atoi(odsAttr(start, nsTable, local))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+func (o Ods) SheetList(excelFile string) ([]string, error) {
+	tables, err := o.readTables(excelFile, false)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(tables))
+	for _, t := range tables {
+		res = append(res, t.name)
+	}
+	return res, nil
+}
+
+func (o Ods) Workbook(excelFile string, opts ...WorkbookOpt) (*WorkbookInfo, error) {
+	wo := newWorkbookOptions(opts)
+	tables, err := o.readTables(excelFile, true)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &WorkbookInfo{}
+	for i, t := range tables {
+		info := SheetInfo{Index: i, Name: t.name, Visible: !t.hidden}
+		statRaw(&info, t.rows, wo.rowStats)
+		res.Sheets = append(res.Sheets, info)
+	}
+
+	if err := o.readMeta(excelFile, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type odsMeta struct {
+	Meta struct {
+		Title          string `xml:"http://purl.org/dc/elements/1.1/ title"`
+		InitialCreator string `xml:"urn:oasis:names:tc:opendocument:xmlns:meta:1.0 initial-creator"`
+		Creator        string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		CreationDate   string `xml:"urn:oasis:names:tc:opendocument:xmlns:meta:1.0 creation-date"`
+		Date           string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	} `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 meta"`
+}
+
+// readMeta 读取 meta.xml 中的文档属性, 没有 meta.xml 时忽略
+func (o Ods) readMeta(excelFile string, res *WorkbookInfo) error {
+	zr, err := zip.OpenReader(excelFile)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	rc, err := o.openPart(zr, excelFile, "meta.xml")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	meta := odsMeta{}
+	if err := xml.NewDecoder(rc).Decode(&meta); err != nil {
+		return errors.Wrap(err, "meta.xml")
+	}
+	res.Title = meta.Meta.Title
+	res.Author = meta.Meta.InitialCreator
+	res.LastModifiedBy = meta.Meta.Creator
+	res.Created = parsePropTime(meta.Meta.CreationDate)
+	res.Modified = parsePropTime(meta.Meta.Date)
+	return nil
+}
+
+type odsTableInfo struct {
+	name      string
+	styleName string
+	hidden    bool
+	rows      [][]string
+}
+
+// readTables 按顺序读取所有 table:table, withRows=false 时不读取行
+func (o Ods) readTables(excelFile string, withRows bool) ([]odsTableInfo, error) {
+	zr, err := zip.OpenReader(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	rc, err := o.openPart(zr, excelFile, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	tables := make([]odsTableInfo, 0)
+	// hiddenStyles table:display="false" 的表格样式
+	hiddenStyles := make(map[string]bool, 0)
+	styleName := ""
+
+	dec := xml.NewDecoder(rc)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "content.xml")
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case start.Name.Space == nsStyle && start.Name.Local == "style":
+			styleName = odsAttr(start, nsStyle, "name")
+		case start.Name.Space == nsStyle && start.Name.Local == "table-properties":
+			if odsAttr(start, nsTable, "display") == "false" {
+				hiddenStyles[styleName] = true
+			}
+		case start.Name.Space == nsTable && start.Name.Local == "table":
+			t := odsTableInfo{
+				name:      odsAttr(start, nsTable, "name"),
+				styleName: odsAttr(start, nsTable, "style-name"),
+			}
+			if withRows {
+				t.rows, err = readOdsRows(dec)
+			} else {
+				err = dec.Skip()
+			}
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, t)
+		}
+	}
+
+	for i := range tables {
+		tables[i].hidden = hiddenStyles[tables[i].styleName]
+	}
+	return tables, nil
+}
+
+type odsTable struct {
+	name string
+	rows [][]string
+}
+
+// SaveOds 写为 .ods 文件, sheet 名同 Save
+func (e Sheet) SaveOds(fileName string) error {
+	rows := make([][]string, 0, len(e.rows)+1)
+	rows = append(rows, e.titleRow())
+	rows = append(rows, e.rows...)
+	return writeOds(fileName, []odsTable{{name: "sheet1", rows: rows}})
+}
+
+// SaveOds 写为 .ods 文件, sheet 顺序与添加顺序一致
+func (f *Fixture) SaveOds(fileName string) error {
+	tables := make([]odsTable, 0, len(f.sheets))
+	for _, s := range f.sheets {
+		tables = append(tables, odsTable{name: s.name, rows: s.rawRows()})
+	}
+	return writeOds(fileName, tables)
+}
+
+// writeOds 所有单元格均写为字符串
+func writeOds(fileName string, tables []odsTable) error {
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+
+	// mimetype 必须是第一个文件且不压缩
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, odsMimeType); err != nil {
+		return err
+	}
+
+	w, err = zw.Create("META-INF/manifest.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, odsManifest); err != nil {
+		return err
+	}
+
+	w, err = zw.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeOdsContent(w, tables); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, buf.Bytes(), 0644)
+}
+
+const odsManifest = xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:media-type="` + odsMimeType + `"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+func writeOdsContent(w io.Writer, tables []odsTable) error {
+	buf := strings.Builder{}
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<office:document-content xmlns:office="` + nsOffice + `" xmlns:table="` + nsTable + `" xmlns:text="` + nsText + `" office:version="1.2">`)
+	buf.WriteString(`<office:body><office:spreadsheet>`)
+	for _, t := range tables {
+		buf.WriteString(`<table:table table:name="`)
+		odsEscape(&buf, t.name)
+		buf.WriteString(`">`)
+		for _, row := range t.rows {
+			buf.WriteString(`<table:table-row>`)
+			for _, cell := range row {
+				if cell == "" {
+					buf.WriteString(`<table:table-cell/>`)
+					continue
+				}
+				buf.WriteString(`<table:table-cell office:value-type="string">`)
+				for _, p := range strings.Split(cell, "\n") {
+					buf.WriteString(`<text:p>`)
+					odsEscapeText(&buf, p)
+					buf.WriteString(`</text:p>`)
+				}
+				buf.WriteString(`</table:table-cell>`)
+			}
+			buf.WriteString(`</table:table-row>`)
+		}
+		buf.WriteString(`</table:table>`)
+	}
+	buf.WriteString(`</office:spreadsheet></office:body></office:document-content>`)
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func odsEscape(buf *strings.Builder, s string) {
+	_ = xml.EscapeText(buf, []byte(s))
+}
+
+// odsEscapeText 连续空格和制表符在 text:p 中会被合并, 需要写为 text:s/text:tab
+func odsEscapeText(buf *strings.Builder, s string) {
+	spaces := 0
+	flush := func() {
+		if spaces == 0 {
+			return
+		}
+		buf.WriteString(`<text:s text:c="` + strconv.Itoa(spaces) + `"/>`)
+		spaces = 0
+	}
+	for _, r := range s {
+		switch r {
+		case ' ':
+			spaces++
+		case '\t':
+			flush()
+			buf.WriteString(`<text:tab/>`)
+		default:
+			flush()
+			odsEscape(buf, string(r))
+		}
+	}
+	flush()
+}
@@ -0,0 +1,134 @@
+package excel
+
+import (
+	"archive/zip"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const odsContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2">
+ <office:body>
+  <office:spreadsheet>
+   <table:table table:name="Other">
+    <table:table-row><table:table-cell office:value-type="string"><text:p>skip</text:p></table:table-cell></table:table-row>
+   </table:table>
+   <table:table table:name="Sheet1">
+    <table:table-column table:number-columns-repeated="5"/>
+    <table:table-header-rows>
+     <table:table-row>
+      <table:table-cell office:value-type="string"><text:p>id</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>name</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>point</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>time</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>status</text:p></table:table-cell>
+     </table:table-row>
+    </table:table-header-rows>
+    <table:table-row>
+     <table:table-cell office:value-type="float" office:value="1"><text:p>1</text:p></table:table-cell>
+     <table:table-cell office:value-type="string"><office:annotation><text:p>note</text:p></office:annotation><text:p>jack<text:s text:c="2"/>ma</text:p></table:table-cell>
+     <table:table-cell office:value-type="float" office:value="17.23"/>
+     <table:table-cell office:value-type="string"><text:p>2023-08-07 00:34:00</text:p></table:table-cell>
+     <table:table-cell office:value-type="float" office:value="1"><text:p>1</text:p></table:table-cell>
+     <table:table-cell table:number-columns-repeated="1019"/>
+    </table:table-row>
+    <table:table-row table:number-rows-repeated="2">
+     <table:table-cell table:number-columns-repeated="1024"/>
+    </table:table-row>
+    <table:table-row>
+     <table:table-cell office:value-type="float" office:value="2"><text:p>2</text:p></table:table-cell>
+     <table:table-cell office:value-type="string"><text:p>tom</text:p><text:p><text:span>line2</text:span></text:p></table:table-cell>
+     <table:table-cell table:number-columns-repeated="2"/>
+     <table:table-cell office:value-type="float" office:value="2"><text:p>2</text:p></table:table-cell>
+    </table:table-row>
+    <table:table-row table:number-rows-repeated="1048570">
+     <table:table-cell table:number-columns-repeated="1024"/>
+    </table:table-row>
+   </table:table>
+  </office:spreadsheet>
+ </office:body>
+</office:document-content>`
+
+func writeOdsFixture(fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(odsContent)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func TestOds(t *testing.T) {
+	Convey("ods", t, func() {
+		Convey("read", func() {
+			fileName := filepath.Join(t.TempDir(), "data.ods")
+			So(writeOdsFixture(fileName), ShouldBeNil)
+
+			sheet, err := Ods{}.GetSheet(fileName, "Sheet1", FirstRowAsTitles())
+			So(err, ShouldBeNil)
+			So(sheet.Titles(), ShouldResemble, Titles{0: "id", 1: "name", 2: "point", 3: "time", 4: "status"})
+			So(sheet.Rows(), ShouldResemble, [][]string{
+				{"1", "jack  ma", "17.23", "2023-08-07 00:34:00", "1"},
+				{},
+				{},
+				{"2", "tom\nline2", "", "", "2"},
+			})
+
+			n, err := Ods{}.CountRows(fileName, "Sheet1", FirstRowAsTitles())
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 4)
+
+			_, err = Ods{}.GetSheet(fileName, "Sheet9")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("reader", func() {
+			fileName := filepath.Join(t.TempDir(), "data.ods")
+			So(writeOdsFixture(fileName), ShouldBeNil)
+
+			p := NewReader(ReaderConfig{SheetWithTitle: true, KeyFrom: KeyFromTag, KeyTagName: "json", Source: Ods{}})
+			retI, err := p.ParseRange(typX{}, fileName, "Sheet1", 3, 1)
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typX)
+			So(len(ret), ShouldEqual, 1)
+			So(ret[0].Id, ShouldEqual, 2)
+			So(ret[0].Status, ShouldEqual, 2)
+		})
+
+		Convey("write", func() {
+			fileName := filepath.Join(t.TempDir(), "fixture.ods")
+			fixture := NewFixture().
+				Sheet("Sheet1").Header("id", "name").Row("1", "a  b\tc").Row("2", "x\ny").
+				Sheet("Sheet2").Row("", "only second").
+				Fixture()
+			So(fixture.SaveOds(fileName), ShouldBeNil)
+
+			mem := fixture.Memory("mem")
+			for _, sheetName := range []string{"Sheet1", "Sheet2"} {
+				want, err := mem.GetSheet("mem", sheetName, FirstRowAsTitles())
+				So(err, ShouldBeNil)
+				got, err := Ods{}.GetSheet(fileName, sheetName, FirstRowAsTitles())
+				So(err, ShouldBeNil)
+				So(got, ShouldResemble, want)
+			}
+
+			sheetFile := filepath.Join(t.TempDir(), "sheet.ods")
+			So(Sheet{titles: Titles{0: "id"}, rows: [][]string{{"1"}}}.SaveOds(sheetFile), ShouldBeNil)
+			got, err := Ods{}.GetSheet(sheetFile, "sheet1", FirstRowAsTitles())
+			So(err, ShouldBeNil)
+			So(got.Rows(), ShouldResemble, [][]string{{"1"}})
+		})
+	})
+}
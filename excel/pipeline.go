@@ -0,0 +1,289 @@
+package excel
+
+import (
+	"context"
+	"github.com/pkg/errors"
+)
+
+// Sink 导入流水线的最后一个阶段, 负责把数据写入存储
+type Sink[T any] interface {
+	// Write 写入一批数据, 返回成功写入的条数
+	Write(items []T) (int, error)
+}
+
+// MemorySink 写入内存, 一般用于测试或小数据量
+type MemorySink[T any] struct {
+	items []T
+}
+
+func NewMemorySink[T any]() *MemorySink[T] {
+	return &MemorySink[T]{}
+}
+
+func (s *MemorySink[T]) Write(items []T) (int, error) {
+	s.items = append(s.items, items...)
+	return len(items), nil
+}
+
+func (s *MemorySink[T]) Items() []T {
+	return s.items
+}
+
+// BatchSink 按 size 分批回调 fn, 遇到错误即停止
+type BatchSink[T any] struct {
+	size int
+	fn   func(batch []T) error
+}
+
+// NewBatchSink size <= 0 时一次性写入
+func NewBatchSink[T any](size int, fn func(batch []T) error) *BatchSink[T] {
+	return &BatchSink[T]{
+		size: size,
+		fn:   fn,
+	}
+}
+
+func (s *BatchSink[T]) Write(items []T) (int, error) {
+	size := s.size
+	if size <= 0 {
+		size = len(items)
+	}
+
+	written := 0
+	for written < len(items) {
+		end := written + size
+		if end > len(items) {
+			end = len(items)
+		}
+		if err := s.fn(items[written:end]); err != nil {
+			return written, errors.Wrapf(err, "batch [%d,%d)", written, end)
+		}
+		written = end
+	}
+	return written, nil
+}
+
+type PipelineStage string
+
+const (
+	StageParse     PipelineStage = "parse"
+	StageValidate  PipelineStage = "validate"
+	StageTransform PipelineStage = "transform"
+	StageDedup     PipelineStage = "dedup"
+	StageSink      PipelineStage = "sink"
+)
+
+// StageCounter 单个阶段的计数
+type StageCounter struct {
+	In     int
+	Out    int
+	Failed int
+}
+
+// RowError 某一数据行(不含标题行, 从0开始)在某个阶段的错误
+type RowError struct {
+	Row   int
+	Stage PipelineStage
+	Err   error
+}
+
+// DedupConflict 与先出现的行 key 相同而被丢弃的行
+type DedupConflict struct {
+	Key      string
+	FirstRow int
+	Row      int
+}
+
+type PipelineResult struct {
+	Counters  map[PipelineStage]StageCounter
+	Errors    []RowError
+	Conflicts []DedupConflict
+}
+
+// Pipeline 导入流水线: 解析 -> 校验 -> 转换 -> 去重 -> 写入.
+// T 必须是 struct, 由 Reader 按 ReaderConfig 解析.
+// 与 Reader.Parse 不同, 单元格解析失败的行(见 Reader.RowErrors)不会进入后续阶段,
+// 只记录在 PipelineResult.Errors 中.
+type Pipeline[T any] struct {
+	reader    Reader
+	excelFile string
+	sheetName string
+
+	validators []func(item T) error
+	transforms []func(item T) (T, error)
+	key        func(item T) string
+	sink       Sink[T]
+}
+
+func NewPipeline[T any](r Reader, excelFile, sheetName string) *Pipeline[T] {
+	return &Pipeline[T]{
+		reader:    r,
+		excelFile: excelFile,
+		sheetName: sheetName,
+	}
+}
+
+// Validate 校验失败的行被丢弃, 记录在 PipelineResult.Errors
+func (p *Pipeline[T]) Validate(fn func(item T) error) *Pipeline[T] {
+	p.validators = append(p.validators, fn)
+	return p
+}
+
+// Transform 按添加顺序依次执行, 失败的行被丢弃
+func (p *Pipeline[T]) Transform(fn func(item T) (T, error)) *Pipeline[T] {
+	p.transforms = append(p.transforms, fn)
+	return p
+}
+
+// Dedup 按 key 去重, 保留先出现的行
+func (p *Pipeline[T]) Dedup(key func(item T) string) *Pipeline[T] {
+	p.key = key
+	return p
+}
+
+func (p *Pipeline[T]) Sink(s Sink[T]) *Pipeline[T] {
+	p.sink = s
+	return p
+}
+
+type pipelineRow[T any] struct {
+	row  int
+	item T
+}
+
+// Run 执行流水线.
+// 单行的单元格解析/校验/转换失败不会中断流水线, 该行被丢弃并记录在 PipelineResult.Errors;
+// 读取 sheet 失败或写入失败时返回 error,
+// 此时 PipelineResult 中仍包含已执行阶段的计数.
+func (p *Pipeline[T]) Run() (PipelineResult, error) {
+	return p.RunContext(context.Background())
+}
+
+// RunContext 同 Run, ctx 取消时在读取、逐行处理和写入之前停止并返回 ctx.Err(),
+// PipelineResult 中包含已完成阶段的计数
+func (p *Pipeline[T]) RunContext(ctx context.Context) (PipelineResult, error) {
+	res := PipelineResult{
+		Counters: make(map[PipelineStage]StageCounter, 0),
+	}
+
+	var tmpl T
+	retI, err := p.reader.ParseContext(ctx, tmpl, p.excelFile, p.sheetName)
+	if err != nil {
+		res.Counters[StageParse] = StageCounter{Failed: 1}
+		return res, errors.Wrapf(err, "Parse(%s,%s)", p.excelFile, p.sheetName)
+	}
+	items, ok := retI.([]T)
+	if !ok {
+		return res, errors.Errorf("Parse result %T not []%T", retI, tmpl)
+	}
+	offset := 0
+	if p.reader.sheet != nil {
+		offset = p.reader.sheet.Offset()
+	}
+
+	// 单元格解析失败的行被丢弃
+	rowErrs := p.reader.RowErrors()
+	parseCounter := StageCounter{In: len(items)}
+	rows := make([]pipelineRow[T], 0, len(items))
+	for i, item := range items {
+		if errs, failed := rowErrs[i]; failed {
+			parseCounter.Failed++
+			res.Errors = append(res.Errors, RowError{Row: offset + i, Stage: StageParse, Err: errs})
+			continue
+		}
+		rows = append(rows, pipelineRow[T]{row: offset + i, item: item})
+	}
+	parseCounter.Out = len(rows)
+	res.Counters[StageParse] = parseCounter
+
+	rows, err = p.runStage(ctx, StageValidate, rows, &res, func(item T) (T, error) {
+		for _, fn := range p.validators {
+			if err := fn(item); err != nil {
+				return item, err
+			}
+		}
+		return item, nil
+	})
+	if err != nil {
+		return res, err
+	}
+
+	rows, err = p.runStage(ctx, StageTransform, rows, &res, func(item T) (T, error) {
+		var err error
+		for _, fn := range p.transforms {
+			item, err = fn(item)
+			if err != nil {
+				return item, err
+			}
+		}
+		return item, nil
+	})
+	if err != nil {
+		return res, err
+	}
+
+	rows = p.dedup(rows, &res)
+
+	if p.sink == nil {
+		return res, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	out := make([]T, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.item)
+	}
+	written, err := p.sink.Write(out)
+	res.Counters[StageSink] = StageCounter{In: len(out), Out: written, Failed: len(out) - written}
+	if err != nil {
+		return res, errors.Wrap(err, "sink.Write")
+	}
+	return res, nil
+}
+
+// runStage ctx 取消时返回 ctx.Err(), 该阶段的计数不记录
+func (p *Pipeline[T]) runStage(ctx context.Context, stage PipelineStage, rows []pipelineRow[T], res *PipelineResult, fn func(item T) (T, error)) ([]pipelineRow[T], error) {
+	counter := StageCounter{In: len(rows)}
+	out := make([]pipelineRow[T], 0, len(rows))
+	for _, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item, err := fn(r.item)
+		if err != nil {
+			counter.Failed++
+			res.Errors = append(res.Errors, RowError{Row: r.row, Stage: stage, Err: err})
+			continue
+		}
+		out = append(out, pipelineRow[T]{row: r.row, item: item})
+	}
+	counter.Out = len(out)
+	res.Counters[stage] = counter
+	return out, nil
+}
+
+func (p *Pipeline[T]) dedup(rows []pipelineRow[T], res *PipelineResult) []pipelineRow[T] {
+	counter := StageCounter{In: len(rows)}
+	if p.key == nil {
+		counter.Out = len(rows)
+		res.Counters[StageDedup] = counter
+		return rows
+	}
+
+	seen := make(map[string]int, len(rows))
+	out := make([]pipelineRow[T], 0, len(rows))
+	for _, r := range rows {
+		k := p.key(r.item)
+		if first, exist := seen[k]; exist {
+			counter.Failed++
+			res.Conflicts = append(res.Conflicts, DedupConflict{Key: k, FirstRow: first, Row: r.row})
+			continue
+		}
+		seen[k] = r.row
+		out = append(out, r)
+	}
+	counter.Out = len(out)
+	res.Counters[StageDedup] = counter
+	return out
+}
@@ -0,0 +1,136 @@
+package excel
+
+import (
+	"context"
+	"errors"
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestPipeline_Run(t *testing.T) {
+	Convey("pipeline", t, func() {
+		config := ReaderConfig{
+			SheetWithTitle: true,
+			KeyFrom:        KeyFromTag,
+			KeyTagName:     "json",
+		}
+
+		Convey("all stages", func() {
+			sink := NewMemorySink[typX]()
+			res, err := NewPipeline[typX](NewReader(config), "data.xlsx", "Sheet1").
+				Validate(func(item typX) error {
+					if item.Point < 0 && item.Name == "lucy" {
+						return errors.New("negative point")
+					}
+					return nil
+				}).
+				Transform(func(item typX) (typX, error) {
+					item.Name = strings.ToUpper(item.Name)
+					return item, nil
+				}).
+				Dedup(func(item typX) string {
+					return string(rune('0' + item.Status))
+				}).
+				Sink(sink).
+				Run()
+			So(err, ShouldBeNil)
+
+			So(res.Counters[StageParse], ShouldResemble, StageCounter{In: 4, Out: 4})
+			So(res.Counters[StageValidate], ShouldResemble, StageCounter{In: 4, Out: 3, Failed: 1})
+			So(res.Counters[StageTransform], ShouldResemble, StageCounter{In: 3, Out: 3})
+			So(res.Counters[StageDedup], ShouldResemble, StageCounter{In: 3, Out: 2, Failed: 1})
+			So(res.Counters[StageSink], ShouldResemble, StageCounter{In: 2, Out: 2})
+
+			So(len(res.Errors), ShouldEqual, 1)
+			So(res.Errors[0].Row, ShouldEqual, 2)
+			So(res.Errors[0].Stage, ShouldEqual, StageValidate)
+			So(res.Conflicts, ShouldResemble, []DedupConflict{{Key: "1", FirstRow: 0, Row: 1}})
+
+			items := sink.Items()
+			So(len(items), ShouldEqual, 2)
+			So(items[0].Name, ShouldEqual, "JACK")
+			So(items[1].Name, ShouldEqual, "KITTY")
+		})
+
+		Convey("batch sink", func() {
+			batches := make([]int, 0)
+			sink := NewBatchSink[typX](3, func(batch []typX) error {
+				batches = append(batches, len(batch))
+				return nil
+			})
+			res, err := NewPipeline[typX](NewReader(config), "data.xlsx", "Sheet1").Sink(sink).Run()
+			So(err, ShouldBeNil)
+			So(batches, ShouldResemble, []int{3, 1})
+			So(res.Counters[StageSink], ShouldResemble, StageCounter{In: 4, Out: 4})
+		})
+
+		Convey("batch sink failed", func() {
+			sink := NewBatchSink[typX](3, func(batch []typX) error {
+				if len(batch) == 1 {
+					return errors.New("store down")
+				}
+				return nil
+			})
+			res, err := NewPipeline[typX](NewReader(config), "data.xlsx", "Sheet1").Sink(sink).Run()
+			So(err, ShouldNotBeNil)
+			So(res.Counters[StageSink], ShouldResemble, StageCounter{In: 4, Out: 3, Failed: 1})
+		})
+
+		Convey("cell parse failed", func() {
+			c := config
+			c.Source = NewFixture().Sheet("Sheet1").
+				Header("id", "name", "point", "status").
+				Row("1", "jack", "1.5", "1").
+				Row("x", "tom", "abc", "1").
+				Row("3", "lucy", "2", "2").
+				Fixture().
+				Memory("bad.xlsx")
+			sink := NewMemorySink[typX]()
+			res, err := NewPipeline[typX](NewReader(c), "bad.xlsx", "Sheet1").Sink(sink).Run()
+			So(err, ShouldBeNil)
+			So(res.Counters[StageParse], ShouldResemble, StageCounter{In: 3, Out: 2, Failed: 1})
+			So(len(res.Errors), ShouldEqual, 1)
+			So(res.Errors[0].Row, ShouldEqual, 1)
+			So(res.Errors[0].Stage, ShouldEqual, StageParse)
+
+			errs, ok := res.Errors[0].Err.(reflectUtils.BindErrors)
+			So(ok, ShouldBeTrue)
+			So(len(errs), ShouldEqual, 2)
+			So(errs[0].Key, ShouldEqual, "id")
+			So(errs[1].Key, ShouldEqual, "point")
+			So(errs[1].Value, ShouldEqual, "abc")
+
+			items := sink.Items()
+			So(len(items), ShouldEqual, 2)
+			So(items[1].Name, ShouldEqual, "lucy")
+		})
+
+		Convey("parse failed", func() {
+			_, err := NewPipeline[typX](NewReader(config), "not_exist.xlsx", "Sheet1").Run()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			sink := NewMemorySink[typX]()
+			res, err := NewPipeline[typX](NewReader(config), "data.xlsx", "Sheet1").
+				Validate(func(item typX) error {
+					cancel()
+					return nil
+				}).
+				Sink(sink).
+				RunContext(ctx)
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			So(res.Counters[StageParse], ShouldResemble, StageCounter{In: 4, Out: 4})
+			_, validated := res.Counters[StageValidate]
+			So(validated, ShouldBeFalse)
+			So(len(sink.Items()), ShouldEqual, 0)
+
+			_, err = NewPipeline[typX](NewReader(config), "data.xlsx", "Sheet1").RunContext(ctx)
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,348 @@
+package excel
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type PivotAggregate string
+
+const (
+	PivotSum     PivotAggregate = "Sum"
+	PivotCount   PivotAggregate = "Count"
+	PivotAverage PivotAggregate = "Average"
+	PivotMax     PivotAggregate = "Max"
+	PivotMin     PivotAggregate = "Min"
+)
+
+// PivotSpec 透视表定义, 字段均为数据 sheet 的列名
+type PivotSpec struct {
+	// DataSheet 数据所在 sheet, 必须已由 Writer 写入
+	DataSheet string
+	// PivotSheet 透视表/汇总表所在 sheet.
+	// AddPivotTable 时不存在则创建, 已存在则在其中的 Cell 处添加, 同一 sheet 可以放多个透视表;
+	// AddSummarySheet 时必须不存在
+	PivotSheet string
+	// Cell 透视表左上角, 默认 A1
+	Cell string
+
+	Rows      []string
+	Columns   []string
+	Value     string
+	Aggregate PivotAggregate // 默认 Sum
+}
+
+func (s PivotSpec) aggregate() PivotAggregate {
+	if s.Aggregate == "" {
+		return PivotSum
+	}
+	return s.Aggregate
+}
+
+func (s PivotSpec) valueTitle() string {
+	return string(s.aggregate()) + " of " + s.Value
+}
+
+func (w *Writer) checkPivotSpec(spec PivotSpec) error {
+	if spec.PivotSheet == "" {
+		return errors.New("PivotSpec.PivotSheet empty")
+	}
+	if spec.PivotSheet == spec.DataSheet {
+		return errors.Errorf("PivotSpec.PivotSheet(%s) same as DataSheet", spec.PivotSheet)
+	}
+	// excelize 按 ! 拆分透视表的区域
+	for _, name := range []string{spec.DataSheet, spec.PivotSheet} {
+		if strings.Contains(name, "!") {
+			return errors.Errorf("PivotSpec: sheet name(%s) contains !", name)
+		}
+	}
+	if len(spec.Rows) == 0 {
+		return errors.New("PivotSpec.Rows empty")
+	}
+	if spec.Value == "" {
+		return errors.New("PivotSpec.Value empty")
+	}
+	switch spec.aggregate() {
+	case PivotSum, PivotCount, PivotAverage, PivotMax, PivotMin:
+	default:
+		return errors.Errorf("PivotSpec.Aggregate(%s) not supported", spec.Aggregate)
+	}
+
+	fields := make([]string, 0, len(spec.Rows)+len(spec.Columns)+1)
+	fields = append(fields, spec.Rows...)
+	fields = append(fields, spec.Columns...)
+	fields = append(fields, spec.Value)
+	for _, f := range fields {
+		if _, err := w.columnIndex(spec.DataSheet, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddPivotTable 基于已写入的数据 sheet 生成 excel 原生透视表
+func (w *Writer) AddPivotTable(spec PivotSpec) error {
+	if err := w.checkPivotSpec(spec); err != nil {
+		return errors.Wrap(err, "checkPivotSpec")
+	}
+
+	dataRange, err := w.dataRange(spec.DataSheet)
+	if err != nil {
+		return err
+	}
+
+	if _, exist := w.sheets[spec.PivotSheet]; !exist {
+		if err := w.ensureSheet(spec.PivotSheet); err != nil {
+			return err
+		}
+	}
+
+	cell := spec.Cell
+	if cell == "" {
+		cell = "A1"
+	}
+	col, row, err := excelize.CellNameToCoordinates(cell)
+	if err != nil {
+		return errors.Wrapf(err, "CellNameToCoordinates(%s)", cell)
+	}
+	// 透视表区域由 excel 打开时刷新, 这里只需给出一个合法的预估范围
+	width := len(spec.Rows) + len(spec.Columns) + 1
+	height := w.sheets[spec.DataSheet].rowCount + 2
+	tableRange, err := pivotRange(spec.PivotSheet, col, row, col+width, row+height)
+	if err != nil {
+		return err
+	}
+
+	opts := &excelize.PivotTableOptions{
+		DataRange:       dataRange,
+		PivotTableRange: tableRange,
+		Data: []excelize.PivotTableField{
+			{Data: spec.Value, Name: spec.valueTitle(), Subtotal: string(spec.aggregate())},
+		},
+		RowGrandTotals: true,
+		ColGrandTotals: true,
+		ShowDrill:      true,
+		ShowRowHeaders: true,
+		ShowColHeaders: true,
+		ShowLastColumn: true,
+	}
+	for _, f := range spec.Rows {
+		opts.Rows = append(opts.Rows, excelize.PivotTableField{Data: f})
+	}
+	for _, f := range spec.Columns {
+		opts.Columns = append(opts.Columns, excelize.PivotTableField{Data: f})
+	}
+
+	if err := w.file.AddPivotTable(opts); err != nil {
+		return errors.Wrapf(err, "AddPivotTable(%s)", spec.PivotSheet)
+	}
+	return nil
+}
+
+// AddSummarySheet 计算汇总结果并写为普通 sheet, 供不支持透视表的程序读取.
+//
+// 第一行为标题: Rows 的列名, 之后每个 Columns 取值组合一列(以"/"连接);
+// 没有 Columns 时只有一列, 标题为 "<Aggregate> of <Value>".
+// 分组按取值的字典序排列.
+func (w *Writer) AddSummarySheet(spec PivotSpec) error {
+	if err := w.checkPivotSpec(spec); err != nil {
+		return errors.Wrap(err, "checkPivotSpec")
+	}
+
+	data, err := w.file.GetRows(spec.DataSheet)
+	if err != nil {
+		return errors.Wrapf(err, "GetRows(%s)", spec.DataSheet)
+	}
+	if len(data) > 0 {
+		data = data[1:]
+	}
+
+	rowIdx, err := w.columnIndexes(spec.DataSheet, spec.Rows)
+	if err != nil {
+		return err
+	}
+	colIdx, err := w.columnIndexes(spec.DataSheet, spec.Columns)
+	if err != nil {
+		return err
+	}
+	valIdx, err := w.columnIndex(spec.DataSheet, spec.Value)
+	if err != nil {
+		return err
+	}
+
+	summary := newPivotSummary(spec.aggregate())
+	for _, line := range data {
+		rowKey := pivotKey(line, rowIdx)
+		colKey := pivotKey(line, colIdx)
+		summary.add(rowKey, colKey, cellAt(line, valIdx))
+	}
+
+	rowKeys := summary.sortedRowKeys()
+	colKeys := summary.sortedColKeys()
+
+	titles := make([]string, 0, len(spec.Rows)+len(colKeys))
+	titles = append(titles, spec.Rows...)
+	for _, ck := range colKeys {
+		if len(spec.Columns) == 0 {
+			titles = append(titles, spec.valueTitle())
+		} else {
+			titles = append(titles, strings.Join(ck, "/"))
+		}
+	}
+
+	rows := make([][]interface{}, 0, len(rowKeys))
+	for _, rk := range rowKeys {
+		cells := make([]interface{}, 0, len(titles))
+		for _, v := range rk {
+			cells = append(cells, v)
+		}
+		for _, ck := range colKeys {
+			v, ok := summary.result(rk, ck)
+			if ok {
+				cells = append(cells, v)
+			} else {
+				cells = append(cells, nil)
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	return w.writeRows(context.Background(), spec.PivotSheet, titles, rows)
+}
+
+func (w *Writer) columnIndexes(sheetName string, titles []string) ([]int, error) {
+	res := make([]int, 0, len(titles))
+	for _, t := range titles {
+		i, err := w.columnIndex(sheetName, t)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, i)
+	}
+	return res, nil
+}
+
+func cellAt(line []string, i int) string {
+	if i < len(line) {
+		return line[i]
+	}
+	return ""
+}
+
+func pivotKey(line []string, indexes []int) []string {
+	key := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		key = append(key, cellAt(line, i))
+	}
+	return key
+}
+
+// pivotSummary 按 (行分组, 列分组) 聚合
+type pivotSummary struct {
+	aggregate PivotAggregate
+	rowKeys   map[string][]string
+	colKeys   map[string][]string
+	cells     map[string]*pivotCell
+}
+
+type pivotCell struct {
+	count int
+	sum   float64
+	max   float64
+	min   float64
+}
+
+func newPivotSummary(aggregate PivotAggregate) *pivotSummary {
+	return &pivotSummary{
+		aggregate: aggregate,
+		rowKeys:   make(map[string][]string, 0),
+		colKeys:   make(map[string][]string, 0),
+		cells:     make(map[string]*pivotCell, 0),
+	}
+}
+
+func joinKey(key []string) string {
+	return strings.Join(key, "\x00")
+}
+
+func (s *pivotSummary) add(rowKey, colKey []string, val string) {
+	rk, ck := joinKey(rowKey), joinKey(colKey)
+	s.rowKeys[rk] = rowKey
+	s.colKeys[ck] = colKey
+
+	cell, exist := s.cells[rk+"\x01"+ck]
+	if !exist {
+		cell = &pivotCell{}
+		s.cells[rk+"\x01"+ck] = cell
+	}
+
+	if s.aggregate == PivotCount {
+		if val != "" {
+			cell.count++
+		}
+		return
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil {
+		// 非数值的单元格不参与计算, 与 excel 一致
+		return
+	}
+	if cell.count == 0 || f > cell.max {
+		cell.max = f
+	}
+	if cell.count == 0 || f < cell.min {
+		cell.min = f
+	}
+	cell.count++
+	cell.sum += f
+}
+
+func (s *pivotSummary) result(rowKey, colKey []string) (float64, bool) {
+	cell, exist := s.cells[joinKey(rowKey)+"\x01"+joinKey(colKey)]
+	if !exist {
+		return 0, false
+	}
+
+	switch s.aggregate {
+	case PivotCount:
+		return float64(cell.count), true
+	case PivotAverage:
+		if cell.count == 0 {
+			return 0, false
+		}
+		return cell.sum / float64(cell.count), true
+	case PivotMax:
+		return cell.max, cell.count > 0
+	case PivotMin:
+		return cell.min, cell.count > 0
+	default:
+		return cell.sum, true
+	}
+}
+
+func sortedKeys(keys map[string][]string) [][]string {
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	res := make([][]string, 0, len(names))
+	for _, k := range names {
+		res = append(res, keys[k])
+	}
+	return res
+}
+
+func (s *pivotSummary) sortedRowKeys() [][]string {
+	return sortedKeys(s.rowKeys)
+}
+
+func (s *pivotSummary) sortedColKeys() [][]string {
+	return sortedKeys(s.colKeys)
+}
@@ -0,0 +1,104 @@
+package excel
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const defaultPositionTagName = "excel"
+
+// StructIndexMap 列下标(从0开始) 到 结构体字段 index path 的映射
+type StructIndexMap map[int][]int
+
+// parsePositionTag 解析位置 tag, 支持:
+//
+//	`excel:"idx=3"` 第4列(下标从0开始)
+//	`excel:"col=D"` D 列
+//
+// 未配置位置信息时 ok 返回 false
+func parsePositionTag(tag string) (columnIndex int, ok bool, err error) {
+	if tag == "" || tag == "-" {
+		return 0, false, nil
+	}
+
+	for _, item := range strings.Split(tag, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		switch key {
+		case "idx":
+			i, err := strconv.Atoi(val)
+			if err != nil {
+				return 0, false, errors.Wrapf(err, "invalid idx(%s)", val)
+			}
+			if i < 0 {
+				return 0, false, errors.Errorf("invalid idx(%d)", i)
+			}
+			return i, true, nil
+		case "col":
+			n, err := excelize.ColumnNameToNumber(val)
+			if err != nil {
+				return 0, false, errors.Wrapf(err, "invalid col(%s)", val)
+			}
+			return n - 1, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// getStructIndexMap 根据位置 tag 生成 列下标->字段 的映射,
+// 会递归 Anonymous struct, 忽略未导出字段和未配置位置的字段.
+// 下标超出 excel 的最大列数(excelize.MaxColumns)时返回错误; sheet 中没有数据的列按空单元格处理.
+func getStructIndexMap(structTyp reflect.Type, tagName string) (StructIndexMap, error) {
+	if tagName == "" {
+		tagName = defaultPositionTagName
+	}
+	res := make(StructIndexMap, 0)
+	err := walkPositionFields(structTyp, nil, tagName, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func walkPositionFields(structTyp reflect.Type, parentIndex []int, tagName string, res StructIndexMap) error {
+	for i := 0; i < structTyp.NumField(); i++ {
+		ft := structTyp.Field(i)
+
+		index := make([]int, 0, len(parentIndex)+1)
+		index = append(index, parentIndex...)
+		index = append(index, i)
+
+		if ft.Type.Kind() == reflect.Struct && ft.Anonymous {
+			if err := walkPositionFields(ft.Type, index, tagName, res); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ft.IsExported() {
+			continue
+		}
+
+		columnIndex, ok, err := parsePositionTag(ft.Tag.Get(tagName))
+		if err != nil {
+			return errors.Wrapf(err, "field(%s)", ft.Name)
+		}
+		if !ok {
+			continue
+		}
+		if columnIndex >= excelize.MaxColumns {
+			return errors.Errorf("field(%s) column index %d out of range, max %d columns", ft.Name, columnIndex, excelize.MaxColumns)
+		}
+		if exist, dup := res[columnIndex]; dup {
+			return errors.Errorf("field(%s) column index %d already mapped to field index %v", ft.Name, columnIndex, exist)
+		}
+		res[columnIndex] = index
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package excel
+
+import (
+	"context"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"strings"
+)
+
+// Progress 导入/导出进度
+type Progress struct {
+	// Rows 已处理的数据行数(不含标题行)
+	Rows int
+	// TotalRows 预估的数据行总数, 0 表示未知.
+	// 读取时取自 sheet 的 dimension, 末尾空行会导致偏大.
+	TotalRows int
+
+	// Bytes 已读取的文件字节数, 仅读取时有
+	Bytes int64
+	// TotalBytes 文件大小, 仅读取时有
+	TotalBytes int64
+}
+
+type ProgressFunc func(p Progress)
+
+// WithContext ctx 取消时停止读取, 返回 ctx.Err()
+func WithContext(ctx context.Context) Opt {
+	return func(p *Parser) {
+		p.ctx = ctx
+	}
+}
+
+// WithProgress 读取文件和每读取一行时回调
+func WithProgress(fn ProgressFunc) Opt {
+	return func(p *Parser) {
+		p.progress = fn
+	}
+}
+
+func (p Parser) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+func (p Parser) report(progress Progress) {
+	if p.progress != nil {
+		p.progress(progress)
+	}
+}
+
+// progressReader 统计读取的字节数, 并在 ctx 取消后中断读取
+type progressReader struct {
+	ctx    context.Context
+	r      io.Reader
+	parser Parser
+
+	read  int64
+	total int64
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(b)
+	r.read += int64(n)
+	if n > 0 {
+		r.parser.report(Progress{Bytes: r.read, TotalBytes: r.total})
+	}
+	return n, err
+}
+
+// estimateRows 根据 sheet 的 dimension(如 A1:E5) 预估数据行数
+func estimateRows(f *excelize.File, sheetName string, parser Parser) int {
+	dimension, err := f.GetSheetDimension(sheetName)
+	if err != nil || dimension == "" {
+		return 0
+	}
+	cells := strings.Split(dimension, ":")
+	_, lastRow, err := excelize.CellNameToCoordinates(cells[len(cells)-1])
+	if err != nil {
+		return 0
+	}
+
+	n := lastRow
+	if parser.WithTitle() {
+		n--
+	}
+	n -= parser.offset
+	if parser.limit > 0 && n > parser.limit {
+		n = parser.limit
+	}
+	if n < 0 {
+		n = 0
+	}
+	return n
+}
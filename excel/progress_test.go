@@ -0,0 +1,99 @@
+package excel
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"path/filepath"
+	"testing"
+)
+
+func TestReader_ParseContext(t *testing.T) {
+	Convey("context", t, func() {
+		progresses := make([]Progress, 0)
+		config := ReaderConfig{
+			SheetWithTitle: true,
+			KeyFrom:        KeyFromTag,
+			KeyTagName:     "json",
+			Progress: func(p Progress) {
+				progresses = append(progresses, p)
+			},
+		}
+
+		Convey("progress", func() {
+			p := NewReader(config)
+			retI, err := p.ParseContext(context.Background(), typX{}, "data.xlsx", "Sheet1")
+			So(err, ShouldBeNil)
+			So(len(retI.([]typX)), ShouldEqual, 4)
+
+			So(len(progresses), ShouldBeGreaterThan, 4)
+			last := progresses[len(progresses)-1]
+			So(last.Rows, ShouldEqual, 4)
+			So(last.TotalRows, ShouldEqual, 4)
+			So(last.TotalBytes, ShouldBeGreaterThan, 0)
+			So(last.Bytes, ShouldEqual, last.TotalBytes)
+		})
+
+		Convey("canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			p := NewReader(config)
+			_, err := p.ParseContext(ctx, typX{}, "data.xlsx", "Sheet1")
+			So(err, ShouldNotBeNil)
+			So(len(progresses), ShouldEqual, 0)
+		})
+
+		Convey("canceled while reading rows", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			config.Progress = func(p Progress) {
+				if p.Rows == 2 {
+					cancel()
+				}
+			}
+
+			p := NewReader(config)
+			_, err := p.ParseContext(ctx, typX{}, "data.xlsx", "Sheet1")
+			So(errors.Cause(err), ShouldEqual, context.Canceled)
+		})
+
+		Convey("range canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			p := NewReader(config)
+			_, err := p.ParseRangeContext(ctx, typX{}, "data.xlsx", "Sheet1", 1, 2)
+			So(errors.Cause(err), ShouldEqual, context.Canceled)
+
+			retI, err := p.ParseRangeContext(context.Background(), typX{}, "data.xlsx", "Sheet1", 1, 2)
+			So(err, ShouldBeNil)
+			So(len(retI.([]typX)), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestSheet_SaveContext(t *testing.T) {
+	Convey("save context", t, func() {
+		sheet := Sheet{
+			titles: Titles{0: "id"},
+			rows:   [][]string{{"1"}, {"2"}, {"3"}},
+		}
+
+		Convey("progress", func() {
+			rows := make([]int, 0)
+			err := sheet.SaveContext(context.Background(), filepath.Join(t.TempDir(), "a.xlsx"), func(p Progress) {
+				So(p.TotalRows, ShouldEqual, 3)
+				rows = append(rows, p.Rows)
+			})
+			So(err, ShouldBeNil)
+			So(rows, ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := sheet.SaveContext(ctx, filepath.Join(t.TempDir(), "a.xlsx"), nil)
+			So(errors.Cause(err), ShouldEqual, context.Canceled)
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package excel
 
 import (
+	"context"
 	"fmt"
 	"github.com/JfL0unch/goUtil/reflectUtils"
 	"github.com/pkg/errors"
@@ -14,19 +15,52 @@ type ReaderConfig struct {
 	//		column name  of sheet  to field name of struct.
 	//
 	// false:
-	//		column index of sheet  to field index of struct,
-	//		one to one strictly.
-	// 		len(field) < len(column).
+	//		column index of sheet  to field of struct,
+	//		by position tag, e.g. `excel:"idx=3"` or `excel:"col=D"`.
+	//		columns without mapped field are skipped.
 	SheetWithTitle bool
 
 	KeyFrom    KeyFrom
 	KeyTagName string // todo 支持 gorm.column这种格式
+
+	// PositionTagName SheetWithTitle=false 时位置 tag 的名字, 默认 excel
+	PositionTagName string
+
+	// Progress 读取进度回调, 可为空
+	Progress ProgressFunc
+
+	// Source 读取 sheet 的实现, 默认 Xuri
+	Source Intf
+
+	// ParseOpts 单元格解析选项, 如 reflectUtils.WithConverter
+	ParseOpts []reflectUtils.ParseOpt
+
+	// Defaults 空单元格和缺少的列对应的字段使用 default tag 的值, 值为 0/false 等零值的单元格不受影响.
+	// 默认值的解析同 reflectUtils.ApplyDefaults, 但只处理列对应的字段, 不进入嵌套的结构体
+	Defaults bool
 }
 type Reader struct {
 	config         ReaderConfig
 	structTmpl     interface{}
 	sheet          *Sheet
 	structFieldMap StructFieldMap
+	structIndexMap StructIndexMap
+	// mediaCols Image/Link 类型字段对应的列
+	mediaCols []int
+	// columnFields 列下标 -> 字段, 没有对应字段的列为 nil
+	columnFields []*reflectUtils.FieldInfo
+	// defaultFields Defaults=true 时带 default tag 的字段
+	defaultFields []defaultField
+	parseOpts     *reflectUtils.ParseOptions
+	// rowErrors 行下标 -> 单元格解析错误
+	rowErrors map[int]reflectUtils.BindErrors
+}
+
+// defaultField 带 default tag 的字段, col 为对应的列, 没有对应的列时为 -1
+type defaultField struct {
+	f   *reflectUtils.FieldInfo
+	col int
+	val string
 }
 
 func NewReader(c ReaderConfig) Reader {
@@ -49,19 +83,75 @@ func (r *Reader) paramCheckOk(propType interface{}, excelFile, sheetName string)
 }
 
 func (r *Reader) Parse(structTmpl interface{}, excelFile, sheetName string) (interface{}, error) {
-	if msg, ok := r.paramCheckOk(structTmpl, excelFile, sheetName); !ok {
-		return nil, errors.New("r.paramCheckOk failed:" + msg)
+	return r.ParseContext(context.Background(), structTmpl, excelFile, sheetName)
+}
+
+// ParseContext 同 Parse, ctx 取消(如客户端断开)时尽快返回 ctx.Err()
+func (r *Reader) ParseContext(ctx context.Context, structTmpl interface{}, excelFile, sheetName string) (interface{}, error) {
+	return r.parse(ctx, structTmpl, excelFile, sheetName, r.sheetOpts()...)
+}
+
+// ParseRange 只解析 [offset, offset+limit) 范围内的数据行(不含标题行),
+// limit <= 0 表示读到末尾.
+// 适用于大文件分页预览, 以及从记录的 offset 处断点续读.
+func (r *Reader) ParseRange(structTmpl interface{}, excelFile, sheetName string, offset, limit int) (interface{}, error) {
+	return r.ParseRangeContext(context.Background(), structTmpl, excelFile, sheetName, offset, limit)
+}
+
+// ParseRangeContext 同 ParseRange, ctx 取消时尽快返回 ctx.Err()
+func (r *Reader) ParseRangeContext(ctx context.Context, structTmpl interface{}, excelFile, sheetName string, offset, limit int) (interface{}, error) {
+	if offset < 0 {
+		return nil, errors.Errorf("ParseRange invalid offset(%d)", offset)
 	}
+	opts := append(r.sheetOpts(), RowOffset(offset), RowLimit(limit))
+	return r.parse(ctx, structTmpl, excelFile, sheetName, opts...)
+}
 
-	r.structTmpl = structTmpl
+// CountRows 数据行数(不含标题行), 不做结构体转换;
+// Source 没有实现 RowCounter 时读取整个 sheet 后计数
+func (r *Reader) CountRows(excelFile, sheetName string) (int, error) {
+	if counter, ok := r.source().(RowCounter); ok {
+		n, err := counter.CountRows(excelFile, sheetName, r.sheetOpts()...)
+		if err != nil {
+			return 0, errors.Wrapf(err, "CountRows(%s,%s)", excelFile, sheetName)
+		}
+		return n, nil
+	}
 
-	x := Xuri{}
-	sheet, err := x.GetSheet(excelFile, sheetName, FirstRowAsTitles())
+	sheet, err := r.source().GetSheet(excelFile, sheetName, r.sheetOpts()...)
 	if err != nil {
-		return nil, errors.Wrapf(err, "GetSheet(%s,%s)", excelFile, sheetName)
+		return 0, errors.Wrapf(err, "GetSheet(%s,%s)", excelFile, sheetName)
+	}
+	if sheet == nil {
+		return 0, nil
+	}
+	return len(sheet.Rows()), nil
+}
+
+func (r *Reader) source() Intf {
+	if r.config.Source == nil {
+		return Xuri{}
 	}
+	return r.config.Source
+}
 
-	r.sheet = sheet
+func (r *Reader) sheetOpts() []Opt {
+	opts := make([]Opt, 0, 2)
+	if r.config.SheetWithTitle {
+		opts = append(opts, FirstRowAsTitles())
+	}
+	if r.config.Progress != nil {
+		opts = append(opts, WithProgress(r.config.Progress))
+	}
+	return opts
+}
+
+func (r *Reader) parse(ctx context.Context, structTmpl interface{}, excelFile, sheetName string, opts ...Opt) (interface{}, error) {
+	if msg, ok := r.paramCheckOk(structTmpl, excelFile, sheetName); !ok {
+		return nil, errors.New("r.paramCheckOk failed:" + msg)
+	}
+
+	r.structTmpl = structTmpl
 
 	structFieldMap, err := r.getStructFieldMap(structTmpl)
 	if err != nil {
@@ -69,53 +159,107 @@ func (r *Reader) Parse(structTmpl interface{}, excelFile, sheetName string) (int
 	}
 	r.structFieldMap = structFieldMap
 
-	return r.ProcessRows()
+	opts = append(opts, WithContext(ctx))
+	if hasMediaField(reflect.TypeOf(structTmpl)) {
+		opts = append(opts, WithMedia(r.mediaSelector()))
+	}
+	sheet, err := r.source().GetSheet(excelFile, sheetName, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "GetSheet(%s,%s)", excelFile, sheetName)
+	}
 
-}
+	r.sheet = sheet
 
-func (r *Reader) getStructInstance(columns []string) reflect.Value {
+	if !r.config.SheetWithTitle && sheet != nil {
+		structIndexMap, err := getStructIndexMap(reflect.TypeOf(structTmpl), r.config.PositionTagName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getStructIndexMap(%v)", structTmpl)
+		}
+		r.structIndexMap = structIndexMap
+	}
 
-	fieldMap := r.structFieldMap
-	sheet := r.sheet
-	sheetTitles := sheet.Titles()
-	sheetWithTitle := r.config.SheetWithTitle
+	return r.processRows(ctx)
 
-	// 1
-	structProto := r.structTmpl
-	structTyp := reflect.TypeOf(structProto)
-	structInstance := reflect.New(structTyp)
+}
 
-	if sheetWithTitle {
-		for columnIndex, columnStr := range columns {
-			fieldName := sheetTitles[columnIndex]
-			parseWithTitle(structInstance, fieldName, columnStr, fieldMap)
-		}
-	} else {
-		for columnIndex, columnVal := range columns {
-			fieldTmpl := structInstance.Field(columnIndex)
-			parsedVal, err := reflectUtils.ParseStrToInstance(fieldTmpl, columnVal)
-			if err == nil {
-				fieldTmpl.Set(parsedVal)
+// getStructInstance 把一行转为结构体, 单元格和默认值的解析错误以 BindErrors 返回, 出错的字段保持零值
+func (r *Reader) getStructInstance(rowIndex int, columns []string) (reflect.Value, reflectUtils.BindErrors) {
+	var errs reflectUtils.BindErrors
+	structInstance := reflect.New(reflect.TypeOf(r.structTmpl))
+	for columnIndex, columnVal := range columns {
+		if columnIndex < len(r.columnFields) {
+			if err := r.parseColumn(structInstance, r.columnFields[columnIndex], columnVal); err != nil {
+				errs = append(errs, &reflectUtils.FieldError{Field: r.columnFields[columnIndex].Path, Key: r.columnKey(columnIndex), Value: columnVal, Err: err})
 			}
 		}
 	}
+	r.setMedia(structInstance, rowIndex)
+	for _, d := range r.defaultFields {
+		if d.col >= 0 && d.col < len(columns) && columns[d.col] != "" {
+			continue
+		}
+		if err := r.parseColumn(structInstance, d.f, d.val); err != nil {
+			errs = append(errs, &reflectUtils.FieldError{Field: d.f.Path, Key: reflectUtils.DefaultTag, Value: d.val, Err: err})
+		}
+	}
 
-	return structInstance.Elem()
+	return structInstance.Elem(), errs
+}
+
+// columnKey 列名, 没有标题行时为列下标
+func (r *Reader) columnKey(col int) string {
+	if titles := r.sheet.Titles(); col < len(titles) {
+		return titles[col]
+	}
+	return strconv.Itoa(col)
+}
+
+// RowErrors 最近一次解析中单元格解析失败的行, key 为返回的 slice 中的下标.
+// 这些行仍包含在返回结果中, 出错的字段为零值
+func (r *Reader) RowErrors() map[int]reflectUtils.BindErrors {
+	return r.rowErrors
 }
 
 func (r *Reader) ProcessRows() (interface{}, error) {
+	return r.processRows(context.Background())
+}
+
+func (r *Reader) processRows(ctx context.Context) (interface{}, error) {
 
 	sheet := r.sheet
 	propStruct := r.structTmpl
 
 	structTyp := reflect.TypeOf(propStruct)
 
+	r.rowErrors = nil
+	if sheet == nil {
+		return reflect.MakeSlice(reflect.SliceOf(structTyp), 0, 0).Interface(), nil
+	}
+
+	r.columnFields = r.getColumnFields(sheet)
+	r.defaultFields = nil
+	if r.config.Defaults {
+		r.defaultFields = r.getDefaultFields()
+	}
+	r.parseOpts = reflectUtils.NewParseOptions(r.config.ParseOpts...)
+	r.rowErrors = map[int]reflectUtils.BindErrors{}
+	r.mediaCols = nil
+	if hasMediaField(structTyp) {
+		r.mediaCols = r.mediaSelector()(sheet.Titles())
+	}
+
 	capSize := len(sheet.rows)
 	structSlice := reflect.MakeSlice(reflect.SliceOf(structTyp), 0, capSize)
 
-	for _, row := range sheet.rows {
+	for i, row := range sheet.rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-		structInstance := r.getStructInstance(row)
+		structInstance, errs := r.getStructInstance(i, row)
+		if len(errs) > 0 {
+			r.rowErrors[i] = errs
+		}
 
 		structSlice = reflect.Append(structSlice, structInstance)
 	}
@@ -126,48 +270,95 @@ func (r *Reader) ProcessRows() (interface{}, error) {
 
 func (r *Reader) getStructFieldMap(structTmpl interface{}) (StructFieldMap, error) {
 	res := make(StructFieldMap, 0)
-	ift := reflect.TypeOf(structTmpl)
-	ifv := reflect.ValueOf(structTmpl)
-	keyFrom := r.config.KeyFrom
-	tagName := r.config.KeyTagName
+	key := keyFunc(r.config.KeyFrom, r.config.KeyTagName)
+	for _, f := range reflectUtils.CachedFields(reflect.TypeOf(structTmpl), r.config.KeyTagName).Fields {
+		res[key(f.Field)] = f.Field
+	}
+	return res, nil
+}
+
+// getColumnFields 列下标 -> 字段, 每个 sheet 只计算一次, 解析单元格时不再按名字查找字段
+func (r *Reader) getColumnFields(sheet *Sheet) []*reflectUtils.FieldInfo {
+	fields := reflectUtils.CachedFields(reflect.TypeOf(r.structTmpl), r.config.KeyTagName).Fields
+	res := make([]*reflectUtils.FieldInfo, sheet.ColumnCount())
+
+	if r.config.SheetWithTitle {
+		key := keyFunc(r.config.KeyFrom, r.config.KeyTagName)
+		byKey := make(map[string]*reflectUtils.FieldInfo, len(fields))
+		for _, f := range fields {
+			// 与 Writer 相同, 列名为空或 - 的字段被忽略
+			if k := key(f.Field); k != "" && k != "-" {
+				byKey[k] = f
+			}
+		}
+		for col, title := range sheet.Titles() {
+			if col < len(res) {
+				res[col] = byKey[title]
+			}
+		}
+		return res
+	}
+
+	for col, index := range r.structIndexMap {
+		for _, f := range fields {
+			if col < len(res) && sameIndex(f.Index, index) {
+				res[col] = f
+			}
+		}
+	}
+	return res
+}
+
+// getDefaultFields 带 default tag 的字段及其对应的列
+func (r *Reader) getDefaultFields() []defaultField {
+	var res []defaultField
+	for _, f := range reflectUtils.CachedFields(reflect.TypeOf(r.structTmpl), r.config.KeyTagName).Fields {
+		val, ok := f.Field.Tag.Lookup(reflectUtils.DefaultTag)
+		if !ok || isMediaType(f.Field.Type) {
+			continue
+		}
+		d := defaultField{f: f, col: -1, val: val}
+		for col, cf := range r.columnFields {
+			if cf == f {
+				d.col = col
+				break
+			}
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyFunc 字段 -> 列名
+func keyFunc(keyFrom KeyFrom, tagName string) func(field reflect.StructField) string {
 	if tagName == "" {
 		tagName = "json"
 	}
-	var key func(_ reflect.StructField) string
 
 	switch keyFrom {
 	case KeyFromTag:
-		key = func(field reflect.StructField) string {
+		return func(field reflect.StructField) string {
 			return field.Tag.Get(tagName)
 		}
 	case KeyFromFieldName:
 		fallthrough
 	default:
-		key = func(field reflect.StructField) string {
+		return func(field reflect.StructField) string {
 			return field.Name
 		}
 	}
-
-	for i := 0; i < ifv.NumField(); i++ {
-		ft := ift.Field(i)
-		fv := ifv.Field(i)
-
-		if ft.Type.Kind() == reflect.Struct && ft.Anonymous {
-			deepFields := reflectUtils.FlatStructFields(fv.Interface())
-			if len(deepFields) > 0 {
-				for _, df := range deepFields {
-					keyName := key(df)
-					res[keyName] = df
-				}
-			}
-		} else {
-			keyName := key(ft)
-
-			res[keyName] = ft
-		}
-
-	}
-	return res, nil
 }
 
 type Opt func(p *Parser)
@@ -178,9 +369,33 @@ func FirstRowAsTitles() Opt {
 	}
 }
 
+// RowOffset 跳过前 n 行数据行(不含标题行)
+func RowOffset(n int) Opt {
+	return func(p *Parser) {
+		p.offset = n
+	}
+}
+
+// RowLimit 最多读取 n 行数据行, n <= 0 表示不限制
+func RowLimit(n int) Opt {
+	return func(p *Parser) {
+		p.limit = n
+	}
+}
+
 type Parser struct {
 	// 第一行是否列名
 	withTitles bool
+
+	// 数据行窗口 [offset, offset+limit)
+	offset int
+	limit  int
+
+	ctx      context.Context
+	progress ProgressFunc
+
+	// media 需要读取图片/超链接的列
+	media MediaSelector
 }
 
 func (p Parser) WithTitle() bool {
@@ -214,14 +429,16 @@ func (p Parser) GetRows(excelData [][]string) ([][]string, error) {
 	}
 }
 
-func parseWithTitle(structToUpdate reflect.Value, fieldName, columnVal string, structMap StructFieldMap) {
-	if field, existField := structMap[fieldName]; existField {
-		fieldTmpl := structToUpdate.Elem().FieldByName(field.Name)
-		nv, err := reflectUtils.ParseStrToInstance(fieldTmpl, columnVal)
-		if err == nil {
-			fieldTmpl.Set(nv)
-		} else {
-			logrus.Errorf("reflectUtils.ParseStrToInstance err:%s", err)
-		}
+// parseColumn 解析单元格并写入字段 f, f 为空或为 Image/Link 类型时跳过
+func (r *Reader) parseColumn(structToUpdate reflect.Value, f *reflectUtils.FieldInfo, columnVal string) error {
+	if f == nil || isMediaType(f.Field.Type) {
+		return nil
+	}
+	nv, err := r.parseOpts.ParseField(f, columnVal)
+	if err != nil {
+		logrus.Errorf("reflectUtils.ParseStrToInstance err:%s", err)
+		return err
 	}
+	structToUpdate.Elem().FieldByIndex(f.Index).Set(nv)
+	return nil
 }
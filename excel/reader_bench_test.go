@@ -0,0 +1,72 @@
+package excel
+
+import (
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func newBenchReader(b *testing.B, rows int) *Reader {
+	fixture := NewFixture().Sheet("Sheet1").Header("id", "name", "point", "time", "status")
+	for i := 0; i < rows; i++ {
+		fixture.Row(strconv.Itoa(i), "jack", "17.23", "2023-08-07 00:34:00", "1")
+	}
+	r := NewReader(ReaderConfig{
+		SheetWithTitle: true,
+		KeyFrom:        KeyFromTag,
+		KeyTagName:     "json",
+		Source:         fixture.Fixture().Memory("mem.xlsx"),
+	})
+	if _, err := r.Parse(typX{}, "mem.xlsx", "Sheet1"); err != nil {
+		b.Fatal(err)
+	}
+	return &r
+}
+
+// legacyStructInstance 缓存之前的逐个单元格按名字查找字段的实现, 作为对照
+func legacyStructInstance(r *Reader, columns []string) reflect.Value {
+	structInstance := reflect.New(reflect.TypeOf(r.structTmpl))
+	titles := r.sheet.Titles()
+	for columnIndex, columnVal := range columns {
+		field, exist := r.structFieldMap[titles[columnIndex]]
+		if !exist {
+			continue
+		}
+		fieldTmpl := structInstance.Elem().FieldByName(field.Name)
+		nv, err := reflectUtils.ParseStrToInstance(fieldTmpl, columnVal, r.config.ParseOpts...)
+		if err == nil {
+			fieldTmpl.Set(nv)
+		}
+	}
+	return structInstance.Elem()
+}
+
+func BenchmarkReader_row(b *testing.B) {
+	r := newBenchReader(b, 1)
+	row := r.sheet.rows[0]
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			legacyStructInstance(r, row)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.getStructInstance(0, row)
+		}
+	})
+}
+
+func BenchmarkReader_Parse(b *testing.B) {
+	r := newBenchReader(b, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Parse(typX{}, "mem.xlsx", "Sheet1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -110,3 +110,158 @@ func TestReader_Parse(t *testing.T) {
 		})
 	})
 }
+
+func TestReader_ParseRange(t *testing.T) {
+	Convey("range", t, func() {
+		config := ReaderConfig{
+			SheetWithTitle: true,
+			KeyFrom:        KeyFromTag,
+			KeyTagName:     "json",
+		}
+		excelFile := "data.xlsx"
+
+		Convey("count", func() {
+			p := NewReader(config)
+			n, err := p.CountRows(excelFile, "Sheet1")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 4)
+		})
+
+		Convey("window", func() {
+			p := NewReader(config)
+			retI, err := p.ParseRange(typX{}, excelFile, "Sheet1", 1, 2)
+			So(err, ShouldBeNil)
+			So(p.sheet.Offset(), ShouldEqual, 1)
+
+			ret := retI.([]typX)
+			So(len(ret), ShouldEqual, 2)
+			So(ret[0].Id, ShouldEqual, 2)
+			So(ret[0].Name, ShouldEqual, "tom")
+			So(ret[1].Id, ShouldEqual, 3)
+			So(ret[1].Name, ShouldEqual, "lucy")
+		})
+
+		Convey("no limit", func() {
+			p := NewReader(config)
+			retI, err := p.ParseRange(typX{}, excelFile, "Sheet1", 3, 0)
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typX)
+			So(len(ret), ShouldEqual, 1)
+			So(ret[0].Name, ShouldEqual, "kitty")
+		})
+
+		Convey("offset out of range", func() {
+			p := NewReader(config)
+			retI, err := p.ParseRange(typX{}, excelFile, "Sheet1", 10, 2)
+			So(err, ShouldBeNil)
+			So(len(retI.([]typX)), ShouldEqual, 0)
+		})
+
+		Convey("negative offset", func() {
+			p := NewReader(config)
+			_, err := p.ParseRange(typX{}, excelFile, "Sheet1", -1, 2)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+type typBase struct {
+	Id uint64 `excel:"idx=0"`
+}
+type typNoTitle struct {
+	typBase
+	secret string
+	Name   string  `excel:"col=C"`
+	Point  float64 `excel:"idx=3"`
+	Status Status  `excel:"col=E"`
+	Remark string
+}
+
+func TestReader_ParseNoTitle(t *testing.T) {
+	Convey("no title", t, func() {
+		config := ReaderConfig{
+			SheetWithTitle: false,
+		}
+		excelFile := "data_no_title.xlsx"
+
+		Convey("by position tag", func() {
+			p := NewReader(config)
+			retI, err := p.Parse(typNoTitle{}, excelFile, "Sheet1")
+			So(err, ShouldBeNil)
+
+			ret := retI.([]typNoTitle)
+			So(len(ret), ShouldEqual, 3)
+			So(ret[0].Id, ShouldEqual, 1)
+			So(ret[0].Name, ShouldEqual, "jack")
+			So(ret[0].Point, ShouldEqual, 17.23)
+			So(ret[0].Status, ShouldEqual, 1)
+			So(ret[0].Remark, ShouldEqual, "")
+			So(ret[2].Id, ShouldEqual, 3)
+			So(ret[2].Name, ShouldEqual, "lucy")
+			So(ret[2].Status, ShouldEqual, 2)
+		})
+
+		Convey("count", func() {
+			p := NewReader(config)
+			n, err := p.CountRows(excelFile, "Sheet1")
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+		})
+
+		Convey("column without data", func() {
+			type typEmpty struct {
+				Id   uint64 `excel:"idx=0"`
+				Name string `excel:"col=Z" default:"none"`
+			}
+			p := NewReader(config)
+			retI, err := p.Parse(typEmpty{}, excelFile, "Sheet1")
+			So(err, ShouldBeNil)
+			ret := retI.([]typEmpty)
+			So(ret[0].Id, ShouldEqual, 1)
+			So(ret[0].Name, ShouldEqual, "")
+
+			c := config
+			c.Defaults = true
+			p = NewReader(c)
+			retI, err = p.Parse(typEmpty{}, excelFile, "Sheet1")
+			So(err, ShouldBeNil)
+			So(retI.([]typEmpty)[0].Name, ShouldEqual, "none")
+		})
+
+		Convey("index out of range", func() {
+			type typOut struct {
+				Id   uint64 `excel:"idx=0"`
+				Name string `excel:"idx=16384"`
+			}
+			p := NewReader(config)
+			_, err := p.Parse(typOut{}, excelFile, "Sheet1")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "out of range")
+		})
+
+		Convey("duplicated index", func() {
+			type typDup struct {
+				Id   uint64 `excel:"idx=0"`
+				Name string `excel:"col=A"`
+			}
+			p := NewReader(config)
+			_, err := p.Parse(typDup{}, excelFile, "Sheet1")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("parsePositionTag", func() {
+			i, ok, err := parsePositionTag("col=AA")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(i, ShouldEqual, 26)
+
+			_, ok, err = parsePositionTag("")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+
+			_, _, err = parsePositionTag("idx=-1")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
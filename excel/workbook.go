@@ -0,0 +1,278 @@
+package excel
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"regexp"
+	"time"
+)
+
+// SheetInfo sheet 的概要信息
+type SheetInfo struct {
+	// Index sheet 在工作簿中的位置(从0开始), 包含隐藏的 sheet
+	Index int
+	Name  string
+	// Dimension 使用区域, 如 A1:E5; xlsx 为文件中记录的值, 其余由数据得到, 空 sheet 为空字符串
+	Dimension string
+	Visible   bool
+	// Rows 行数(含标题行), 末尾的空行不计入, 仅 WithRowStats 时统计
+	Rows int
+	// Columns 各行中的最大列数, 仅 WithRowStats 时统计
+	Columns int
+	// Header 第一行
+	Header []string
+}
+
+type workbookOptions struct {
+	rowStats bool
+}
+
+type WorkbookOpt func(o *workbookOptions)
+
+// WithRowStats 统计 SheetInfo 的 Rows/Columns, xlsx 需要读取全部行
+func WithRowStats() WorkbookOpt {
+	return func(o *workbookOptions) {
+		o.rowStats = true
+	}
+}
+
+func newWorkbookOptions(opts []WorkbookOpt) workbookOptions {
+	o := workbookOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WorkbookInfo 工作簿的 sheet 列表及文档属性
+type WorkbookInfo struct {
+	Sheets []SheetInfo
+
+	Title          string
+	Author         string
+	LastModifiedBy string
+	// Created/Modified 文件中没有记录时为零值
+	Created  time.Time
+	Modified time.Time
+}
+
+// Inspector 可以列出 sheet 的 Intf 实现
+type Inspector interface {
+	// SheetList 按工作簿中的顺序返回 sheet 名, 不读取数据
+	SheetList(excelFile string) ([]string, error)
+
+	// Workbook 默认只读取 sheet 的概要, 不统计行数
+	Workbook(excelFile string, opts ...WorkbookOpt) (*WorkbookInfo, error)
+}
+
+// SheetSelector 从 sheet 名列表中选出一个
+type SheetSelector func(sheetNames []string) (string, error)
+
+// SheetByName 按名字选择, 不存在时返回错误
+func SheetByName(name string) SheetSelector {
+	return func(sheetNames []string) (string, error) {
+		for _, n := range sheetNames {
+			if n == name {
+				return n, nil
+			}
+		}
+		return "", excelize.ErrSheetNotExist{SheetName: name}
+	}
+}
+
+// SheetByIndex 按位置选择(从0开始)
+func SheetByIndex(index int) SheetSelector {
+	return func(sheetNames []string) (string, error) {
+		if index < 0 || index >= len(sheetNames) {
+			return "", errors.Errorf("sheet index %d out of range, workbook has %d sheets", index, len(sheetNames))
+		}
+		return sheetNames[index], nil
+	}
+}
+
+// SheetByRegexp 选择第一个名字匹配 re 的 sheet
+func SheetByRegexp(re *regexp.Regexp) SheetSelector {
+	return func(sheetNames []string) (string, error) {
+		for _, n := range sheetNames {
+			if re.MatchString(n) {
+				return n, nil
+			}
+		}
+		return "", errors.Errorf("no sheet matches %s", re.String())
+	}
+}
+
+// ResolveSheet 按 selector 选出 sheet 名, Source 需要实现 Inspector
+func (r *Reader) ResolveSheet(excelFile string, selector SheetSelector) (string, error) {
+	inspector, ok := r.source().(Inspector)
+	if !ok {
+		return "", errors.Errorf("source %T can not list sheets", r.source())
+	}
+	names, err := inspector.SheetList(excelFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "SheetList(%s)", excelFile)
+	}
+	return selector(names)
+}
+
+// ParseSelected 同 Parse, sheet 由 selector 选出
+func (r *Reader) ParseSelected(structTmpl interface{}, excelFile string, selector SheetSelector) (interface{}, error) {
+	sheetName, err := r.ResolveSheet(excelFile, selector)
+	if err != nil {
+		return nil, err
+	}
+	return r.Parse(structTmpl, excelFile, sheetName)
+}
+
+func (x Xuri) SheetList(excelFile string) ([]string, error) {
+	f, err := excelize.OpenFile(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.GetSheetList(), nil
+}
+
+func (x Xuri) Workbook(excelFile string, opts ...WorkbookOpt) (*WorkbookInfo, error) {
+	o := newWorkbookOptions(opts)
+	f, err := excelize.OpenFile(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res := &WorkbookInfo{}
+	props, err := f.GetDocProps()
+	if err != nil {
+		return nil, errors.Wrap(err, "GetDocProps")
+	}
+	res.Title = props.Title
+	res.Author = props.Creator
+	res.LastModifiedBy = props.LastModifiedBy
+	res.Created = parsePropTime(props.Created)
+	res.Modified = parsePropTime(props.Modified)
+
+	for i, name := range f.GetSheetList() {
+		info := SheetInfo{Index: i, Name: name}
+
+		info.Visible, err = f.GetSheetVisible(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetSheetVisible(%s)", name)
+		}
+		if err := x.sheetStat(f, &info, o.rowStats); err != nil {
+			return nil, err
+		}
+
+		res.Sheets = append(res.Sheets, info)
+	}
+	return res, nil
+}
+
+// sheetStat 流式读取第一行, Dimension 取文件中记录的值;
+// rowStats 为 true 时继续读完全部行统计行数、列数
+func (x Xuri) sheetStat(f *excelize.File, info *SheetInfo, rowStats bool) error {
+	rows, err := f.Rows(info.Name)
+	if err != nil {
+		return errors.Wrapf(err, "Rows(%s)", info.Name)
+	}
+	defer rows.Close()
+
+	cur := 0
+	for rows.Next() {
+		cur++
+		row, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		if cur == 1 {
+			info.Header = row
+			if !rowStats {
+				break
+			}
+		}
+		if len(row) > 0 {
+			info.Rows = cur
+		}
+		if len(row) > info.Columns {
+			info.Columns = len(row)
+		}
+	}
+	if err := rows.Error(); err != nil {
+		return errors.Wrapf(err, "Rows(%s)", info.Name)
+	}
+
+	info.Dimension, err = f.GetSheetDimension(info.Name)
+	if err != nil {
+		return errors.Wrapf(err, "GetSheetDimension(%s)", info.Name)
+	}
+	if info.Header == nil && info.Dimension == "A1" {
+		// 新建 sheet 的默认值
+		info.Dimension = ""
+	}
+	return nil
+}
+
+// statRaw 由全部行得到 SheetInfo 中的使用区域和第一行, rowStats 为 true 时填写行数、列数
+func statRaw(info *SheetInfo, raw [][]string, rowStats bool) {
+	raw = trimEmptyTail(raw)
+	columns := 0
+	for _, row := range raw {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if len(raw) > 0 {
+		info.Header = raw[0]
+	}
+	info.Dimension = dimension(len(raw), columns)
+	if rowStats {
+		info.Rows, info.Columns = len(raw), columns
+	}
+}
+
+// dimension 以 A1 为左上角的区域
+func dimension(rows, columns int) string {
+	if rows == 0 || columns == 0 {
+		return ""
+	}
+	cell, _ := excelize.CoordinatesToCellName(columns, rows)
+	return "A1:" + cell
+}
+
+// parsePropTime 文档属性中的时间, 解析失败返回零值
+func parsePropTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (m *Memory) SheetList(excelFile string) ([]string, error) {
+	f, err := m.file(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(f.sheets))
+	for _, s := range f.sheets {
+		res = append(res, s.name)
+	}
+	return res, nil
+}
+
+func (m *Memory) Workbook(excelFile string, opts ...WorkbookOpt) (*WorkbookInfo, error) {
+	o := newWorkbookOptions(opts)
+	f, err := m.file(excelFile)
+	if err != nil {
+		return nil, err
+	}
+	res := &WorkbookInfo{}
+	for i, s := range f.sheets {
+		info := SheetInfo{Index: i, Name: s.name, Visible: true}
+		statRaw(&info, s.rawRows(), o.rowStats)
+		res.Sheets = append(res.Sheets, info)
+	}
+	return res, nil
+}
@@ -0,0 +1,235 @@
+package excel
+
+import (
+	"archive/zip"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/xuri/excelize/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const odsWorkbookContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2">
+ <office:automatic-styles>
+  <style:style style:name="ta1" style:family="table"><style:table-properties table:display="true"/></style:style>
+  <style:style style:name="ta2" style:family="table"><style:table-properties table:display="false"/></style:style>
+ </office:automatic-styles>
+ <office:body>
+  <office:spreadsheet>
+   <table:table table:name="data" table:style-name="ta1">
+    <table:table-row><table:table-cell><text:p>id</text:p></table:table-cell><table:table-cell><text:p>name</text:p></table:table-cell></table:table-row>
+    <table:table-row><table:table-cell><text:p>1</text:p></table:table-cell><table:table-cell><text:p>jack</text:p></table:table-cell><table:table-cell><text:p>x</text:p></table:table-cell></table:table-row>
+    <table:table-row table:number-rows-repeated="1048574"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+   </table:table>
+   <table:table table:name="hidden" table:style-name="ta2">
+    <table:table-row><table:table-cell><text:p>a</text:p></table:table-cell></table:table-row>
+   </table:table>
+  </office:spreadsheet>
+ </office:body>
+</office:document-content>`
+
+const odsWorkbookMeta = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/" office:version="1.2">
+ <office:meta>
+  <meta:initial-creator>jack</meta:initial-creator>
+  <meta:creation-date>2023-08-07T00:34:00</meta:creation-date>
+  <dc:creator>tom</dc:creator>
+  <dc:date>2023-08-08T10:00:00.123456789</dc:date>
+  <dc:title>report</dc:title>
+ </office:meta>
+</office:document-meta>`
+
+func writeOdsWorkbookFixture(fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{"content.xml": odsWorkbookContent, "meta.xml": odsWorkbookMeta} {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func TestWorkbook(t *testing.T) {
+	Convey("xuri", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "workbook.xlsx")
+
+		w := NewWriter(WriterConfig{})
+		So(w.WriteSheet("data", Sheet{
+			titles: Titles{0: "id", 1: "name", 2: "point"},
+			rows:   [][]string{{"1", "jack", "1.5"}, {"2", "tom"}},
+		}), ShouldBeNil)
+		So(w.WriteSheet("hidden", Sheet{titles: Titles{0: "a"}}), ShouldBeNil)
+		So(w.WriteSheet("report_2023", Sheet{titles: Titles{0: "b"}, rows: [][]string{{"1"}}}), ShouldBeNil)
+		So(w.File().SetSheetVisible("hidden", false), ShouldBeNil)
+		So(w.File().SetDocProps(&excelize.DocProperties{
+			Title:          "report",
+			Creator:        "jack",
+			LastModifiedBy: "tom",
+			Created:        "2023-08-07T00:34:00Z",
+			Modified:       "2023-08-08T10:00:00Z",
+		}), ShouldBeNil)
+		So(w.SaveAs(excelFile), ShouldBeNil)
+
+		names, err := Xuri{}.SheetList(excelFile)
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"data", "hidden", "report_2023"})
+
+		info, err := Xuri{}.Workbook(excelFile)
+		So(err, ShouldBeNil)
+		So(info.Title, ShouldEqual, "report")
+		So(info.Author, ShouldEqual, "jack")
+		So(info.LastModifiedBy, ShouldEqual, "tom")
+		So(info.Created, ShouldEqual, time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC))
+		So(info.Modified, ShouldEqual, time.Date(2023, 8, 8, 10, 0, 0, 0, time.UTC))
+
+		So(len(info.Sheets), ShouldEqual, 3)
+		So(info.Sheets[0], ShouldResemble, SheetInfo{
+			Index:     0,
+			Name:      "data",
+			Dimension: "A1:C3",
+			Visible:   true,
+			Header:    []string{"id", "name", "point"},
+		})
+		So(info.Sheets[1].Visible, ShouldBeFalse)
+		So(info.Sheets[1].Dimension, ShouldEqual, "A1:A1")
+		So(info.Sheets[1].Rows, ShouldEqual, 0)
+		So(info.Sheets[2].Index, ShouldEqual, 2)
+
+		Convey("row stats", func() {
+			info, err := Xuri{}.Workbook(excelFile, WithRowStats())
+			So(err, ShouldBeNil)
+			So(info.Sheets[0], ShouldResemble, SheetInfo{
+				Index:     0,
+				Name:      "data",
+				Dimension: "A1:C3",
+				Visible:   true,
+				Rows:      3,
+				Columns:   3,
+				Header:    []string{"id", "name", "point"},
+			})
+			So(info.Sheets[1].Rows, ShouldEqual, 1)
+			So(info.Sheets[2].Rows, ShouldEqual, 2)
+		})
+
+		Convey("dimension not recorded", func() {
+			fileName := filepath.Join(t.TempDir(), "plain.xlsx")
+			f := excelize.NewFile()
+			So(f.SaveAs(fileName), ShouldBeNil)
+
+			info, err := Xuri{}.Workbook(fileName)
+			So(err, ShouldBeNil)
+			So(info.Sheets, ShouldResemble, []SheetInfo{{Index: 0, Name: "Sheet1", Visible: true}})
+		})
+
+		Convey("select sheet", func() {
+			p := NewReader(ReaderConfig{SheetWithTitle: true, KeyFrom: KeyFromTag, KeyTagName: "json"})
+
+			name, err := p.ResolveSheet(excelFile, SheetByIndex(2))
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "report_2023")
+
+			name, err = p.ResolveSheet(excelFile, SheetByRegexp(regexp.MustCompile(`^report_\d+$`)))
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "report_2023")
+
+			_, err = p.ResolveSheet(excelFile, SheetByIndex(3))
+			So(err, ShouldNotBeNil)
+			_, err = p.ResolveSheet(excelFile, SheetByRegexp(regexp.MustCompile(`^x`)))
+			So(err, ShouldNotBeNil)
+			_, err = p.ResolveSheet(excelFile, SheetByName("x"))
+			So(err, ShouldHaveSameTypeAs, excelize.ErrSheetNotExist{})
+
+			type row struct {
+				Id   int    `json:"id"`
+				Name string `json:"name"`
+			}
+			retI, err := p.ParseSelected(row{}, excelFile, SheetByIndex(0))
+			So(err, ShouldBeNil)
+			So(retI, ShouldResemble, []row{{Id: 1, Name: "jack"}, {Id: 2, Name: "tom"}})
+		})
+	})
+
+	Convey("memory", t, func() {
+		m := NewFixture().
+			Sheet("data").Header("id", "name").Row("1", "jack", "x").Row().
+			Sheet("other").
+			Fixture().Memory("mem.xlsx")
+
+		info, err := m.Workbook("mem.xlsx")
+		So(err, ShouldBeNil)
+		So(info.Sheets, ShouldResemble, []SheetInfo{
+			{Index: 0, Name: "data", Dimension: "A1:C2", Visible: true, Header: []string{"id", "name"}},
+			{Index: 1, Name: "other", Visible: true},
+		})
+
+		info, err = m.Workbook("mem.xlsx", WithRowStats())
+		So(err, ShouldBeNil)
+		So(info.Sheets[0], ShouldResemble, SheetInfo{Index: 0, Name: "data", Dimension: "A1:C2", Visible: true, Rows: 2, Columns: 3, Header: []string{"id", "name"}})
+
+		p := NewReader(ReaderConfig{SheetWithTitle: true, Source: m})
+		name, err := p.ResolveSheet("mem.xlsx", SheetByIndex(1))
+		So(err, ShouldBeNil)
+		So(name, ShouldEqual, "other")
+
+		_, err = m.SheetList("none.xlsx")
+		So(os.IsNotExist(errors.Cause(err)), ShouldBeTrue)
+	})
+
+	Convey("ods", t, func() {
+		fileName := filepath.Join(t.TempDir(), "workbook.ods")
+		So(writeOdsWorkbookFixture(fileName), ShouldBeNil)
+
+		names, err := Ods{}.SheetList(fileName)
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"data", "hidden"})
+
+		info, err := Ods{}.Workbook(fileName, WithRowStats())
+		So(err, ShouldBeNil)
+		So(info.Title, ShouldEqual, "report")
+		So(info.Author, ShouldEqual, "jack")
+		So(info.LastModifiedBy, ShouldEqual, "tom")
+		So(info.Created, ShouldEqual, time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC))
+		So(info.Modified, ShouldEqual, time.Date(2023, 8, 8, 10, 0, 0, 123456789, time.UTC))
+		So(info.Sheets, ShouldResemble, []SheetInfo{
+			{Index: 0, Name: "data", Dimension: "A1:C2", Visible: true, Rows: 2, Columns: 3, Header: []string{"id", "name"}},
+			{Index: 1, Name: "hidden", Dimension: "A1:A1", Visible: false, Rows: 1, Columns: 1, Header: []string{"a"}},
+		})
+
+		info, err = Ods{}.Workbook(fileName)
+		So(err, ShouldBeNil)
+		So(info.Sheets[0], ShouldResemble, SheetInfo{Index: 0, Name: "data", Dimension: "A1:C2", Visible: true, Header: []string{"id", "name"}})
+
+		Convey("without meta.xml", func() {
+			fileName := filepath.Join(t.TempDir(), "data.ods")
+			So(writeOdsFixture(fileName), ShouldBeNil)
+
+			info, err := Ods{}.Workbook(fileName)
+			So(err, ShouldBeNil)
+			So(info.Title, ShouldEqual, "")
+			So(len(info.Sheets), ShouldEqual, 2)
+		})
+	})
+
+	Convey("source without Inspector", t, func() {
+		p := NewReader(ReaderConfig{Source: countOnly{}})
+		_, err := p.ResolveSheet("x.xlsx", SheetByIndex(0))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+// countOnly 不实现 Inspector 的 Intf
+type countOnly struct{ Intf }
@@ -0,0 +1,403 @@
+package excel
+
+import (
+	"context"
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type WriterConfig struct {
+	// 列名来源, 同 ReaderConfig
+	KeyFrom    KeyFrom
+	KeyTagName string
+
+	// Progress 每写入一行数据回调, 可为空
+	Progress ProgressFunc
+}
+
+// Writer 将 Sheet 或 结构体切片 写入 excel 文件,
+// 记录每个 sheet 的列名和行数, 供 透视表/图表 定位数据区域
+type Writer struct {
+	config WriterConfig
+	file   *excelize.File
+	sheets map[string]*writtenSheet
+}
+
+type writtenSheet struct {
+	titles []string
+	// fields 结构体字段名 -> 列下标, 仅 WriteStructs 写入的 sheet 有
+	fields map[string]int
+	// 数据行数(不含标题行)
+	rowCount int
+}
+
+func NewWriter(c WriterConfig) *Writer {
+	return &Writer{
+		config: c,
+		file:   excelize.NewFile(),
+		sheets: make(map[string]*writtenSheet, 0),
+	}
+}
+
+// WriteSheet 写入 Sheet, 第一行为标题
+func (w *Writer) WriteSheet(sheetName string, sheet Sheet) error {
+	return w.WriteSheetContext(context.Background(), sheetName, sheet)
+}
+
+// WriteSheetContext 同 WriteSheet, ctx 取消时停止写入并返回 ctx.Err()
+func (w *Writer) WriteSheetContext(ctx context.Context, sheetName string, sheet Sheet) error {
+	titles := sheet.titleRow()
+	rows := make([][]interface{}, 0, len(sheet.Rows()))
+	for _, row := range sheet.Rows() {
+		cells := make([]interface{}, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, cell)
+		}
+		rows = append(rows, cells)
+	}
+	return w.writeRows(ctx, sheetName, titles, rows)
+}
+
+// WriteStructs 写入结构体切片, 列名由 WriterConfig 决定,
+// Anonymous struct 会被打平, 未导出字段和列名为空/"-"的字段被忽略
+func (w *Writer) WriteStructs(sheetName string, structs interface{}) error {
+	return w.WriteStructsContext(context.Background(), sheetName, structs)
+}
+
+// WriteStructsContext 同 WriteStructs, ctx 取消时停止写入并返回 ctx.Err()
+func (w *Writer) WriteStructsContext(ctx context.Context, sheetName string, structs interface{}) error {
+	sv := reflect.ValueOf(structs)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return errors.Errorf("WriteStructs structs(%s) not slice", sv.Kind().String())
+	}
+	elemTyp := sv.Type().Elem()
+	if elemTyp.Kind() != reflect.Struct {
+		return errors.Errorf("WriteStructs elem(%s) not struct", elemTyp.Kind().String())
+	}
+
+	columns := structColumns(elemTyp, keyFunc(w.config.KeyFrom, w.config.KeyTagName))
+	titles := make([]string, 0, len(columns))
+	fields := make(map[string]int, len(columns))
+	for i, c := range columns {
+		titles = append(titles, c.title)
+		fields[elemTyp.FieldByIndex(c.index).Name] = i
+	}
+
+	rows := make([][]interface{}, 0, sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		item := sv.Index(i)
+		cells := make([]interface{}, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, cellValue(item.FieldByIndex(c.index)))
+		}
+		rows = append(rows, cells)
+	}
+	if err := w.writeRows(ctx, sheetName, titles, rows); err != nil {
+		return err
+	}
+	w.sheets[sheetName].fields = fields
+	return nil
+}
+
+// File 底层 excelize 文件, 用于本包未覆盖的操作
+func (w *Writer) File() *excelize.File {
+	return w.file
+}
+
+func (w *Writer) SaveAs(fileName string) error {
+	w.file.SetActiveSheet(0)
+
+	// Save spreadsheet by the given path.
+	if err := w.file.SaveAs(fileName); err != nil {
+		return errors.Wrapf(err, "SaveAs(%s)", fileName)
+	}
+	return nil
+}
+
+// ensureSheet 第一个 sheet 复用 excelize 默认的 Sheet1
+func (w *Writer) ensureSheet(sheetName string) error {
+	if _, exist := w.sheets[sheetName]; exist {
+		return errors.Errorf("sheet(%s) already written", sheetName)
+	}
+
+	if len(w.sheets) == 0 {
+		if sheetName != "Sheet1" {
+			if err := w.file.SetSheetName("Sheet1", sheetName); err != nil {
+				return errors.Wrapf(err, "SetSheetName('Sheet1',%s)", sheetName)
+			}
+		}
+	} else {
+		if _, err := w.file.NewSheet(sheetName); err != nil {
+			return errors.Wrapf(err, "NewSheet(%s)", sheetName)
+		}
+	}
+	w.sheets[sheetName] = &writtenSheet{}
+	return nil
+}
+
+func (w *Writer) writeRows(ctx context.Context, sheetName string, titles []string, rows [][]interface{}) error {
+	if err := w.ensureSheet(sheetName); err != nil {
+		return err
+	}
+
+	progress := Progress{TotalRows: len(rows)}
+
+	err := w.file.SetSheetRow(sheetName, "A1", &titles)
+	if err != nil {
+		return errors.Wrapf(err, "SetSheetRow(%s,A1)", sheetName)
+	}
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		row, media := splitMedia(row)
+		err = w.file.SetSheetRow(sheetName, cell, &row)
+		if err != nil {
+			return errors.Wrapf(err, "SetSheetRow(%s,%s)", sheetName, cell)
+		}
+		if err := w.writeMedia(sheetName, i+2, media); err != nil {
+			return err
+		}
+
+		if w.config.Progress != nil {
+			progress.Rows++
+			w.config.Progress(progress)
+		}
+	}
+
+	// 记录使用区域, 读取时 Workbook 不必遍历全部行
+	columns := len(titles)
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if ref := dimension(len(rows)+1, columns); ref != "" {
+		if err := w.file.SetSheetDimension(sheetName, ref); err != nil {
+			return errors.Wrapf(err, "SetSheetDimension(%s,%s)", sheetName, ref)
+		}
+	}
+
+	ws := w.sheets[sheetName]
+	ws.titles = titles
+	ws.rowCount = len(rows)
+	return nil
+}
+
+// splitMedia 把 Image/Link 从行中取出, 返回普通单元格和 列下标->Image/Link
+func splitMedia(row []interface{}) ([]interface{}, map[int]interface{}) {
+	var media map[int]interface{}
+	cells := make([]interface{}, len(row))
+	for i, cell := range row {
+		switch val := cell.(type) {
+		case Image:
+			cells[i] = nil
+		case Link:
+			cells[i] = val.Text
+		default:
+			cells[i] = cell
+			continue
+		}
+		if media == nil {
+			media = make(map[int]interface{}, 0)
+		}
+		media[i] = cell
+	}
+	return cells, media
+}
+
+// writeMedia 写入图片和超链接, rowNo 为行号(从1开始)
+func (w *Writer) writeMedia(sheetName string, rowNo int, media map[int]interface{}) error {
+	for col, m := range media {
+		cell, err := excelize.CoordinatesToCellName(col+1, rowNo)
+		if err != nil {
+			return err
+		}
+		switch val := m.(type) {
+		case Image:
+			if len(val.Data) == 0 {
+				continue
+			}
+			pic := &excelize.Picture{
+				Extension: val.Format,
+				File:      val.Data,
+				Format:    &excelize.GraphicOptions{AltText: val.AltText},
+			}
+			if err := w.file.AddPictureFromBytes(sheetName, cell, pic); err != nil {
+				return errors.Wrapf(err, "AddPictureFromBytes(%s,%s)", sheetName, cell)
+			}
+		case Link:
+			if val.Target == "" {
+				continue
+			}
+			linkType := "External"
+			if isLocation(val.Target) {
+				linkType = "Location"
+			}
+			if err := w.file.SetCellHyperLink(sheetName, cell, val.Target, linkType); err != nil {
+				return errors.Wrapf(err, "SetCellHyperLink(%s,%s)", sheetName, cell)
+			}
+		}
+	}
+	return nil
+}
+
+// columnIndex 列在 sheet 中的下标(从0开始),
+// 先按列名查找, 再按结构体字段名查找
+func (w *Writer) columnIndex(sheetName, title string) (int, error) {
+	ws, exist := w.sheets[sheetName]
+	if !exist {
+		return 0, errors.Errorf("sheet(%s) not written", sheetName)
+	}
+	for i, t := range ws.titles {
+		if t == title {
+			return i, nil
+		}
+	}
+	if i, ok := ws.fields[title]; ok {
+		return i, nil
+	}
+	return 0, errors.Errorf("sheet(%s) has no column(%s)", sheetName, title)
+}
+
+// dataRange 整个数据区域(含标题行), 形如 Sheet1!$A$1:$E$5, 供透视表使用
+func (w *Writer) dataRange(sheetName string) (string, error) {
+	ws, exist := w.sheets[sheetName]
+	if !exist {
+		return "", errors.Errorf("sheet(%s) not written", sheetName)
+	}
+	if len(ws.titles) == 0 {
+		return "", errors.Errorf("sheet(%s) has no column", sheetName)
+	}
+	return pivotRange(sheetName, 1, 1, len(ws.titles), ws.rowCount+1)
+}
+
+// cellRange 公式中的绝对引用区域, 形如 'Sheet 1'!$A$1:$E$5, col/row 从1开始
+func cellRange(sheetName string, col1, row1, col2, row2 int) (string, error) {
+	area, err := areaRef(col1, row1, col2, row2)
+	if err != nil {
+		return "", err
+	}
+	return sheetRef(sheetName) + "!" + area, nil
+}
+
+// pivotRange 透视表的区域, sheet 名不加引号: excelize.AddPivotTable 自己按 ! 拆分, 不接受带引号的 sheet 名
+func pivotRange(sheetName string, col1, row1, col2, row2 int) (string, error) {
+	area, err := areaRef(col1, row1, col2, row2)
+	if err != nil {
+		return "", err
+	}
+	return sheetName + "!" + area, nil
+}
+
+// areaRef 绝对引用区域, 不含 sheet 名, 如 $A$1:$E$5
+func areaRef(col1, row1, col2, row2 int) (string, error) {
+	start, err := excelize.CoordinatesToCellName(col1, row1, true)
+	if err != nil {
+		return "", err
+	}
+	end, err := excelize.CoordinatesToCellName(col2, row2, true)
+	if err != nil {
+		return "", err
+	}
+	return start + ":" + end, nil
+}
+
+// sheetRef 公式中引用的 sheet 名, 总是加单引号, 名字中的单引号写两次
+func sheetRef(sheetName string) string {
+	return "'" + strings.ReplaceAll(sheetName, "'", "''") + "'"
+}
+
+type structColumn struct {
+	title string
+	index []int
+}
+
+// structColumns 按字段顺序返回可写入的列
+func structColumns(structTyp reflect.Type, key func(field reflect.StructField) string) []structColumn {
+	res := make([]structColumn, 0, structTyp.NumField())
+	walkStructColumns(structTyp, nil, key, &res)
+	return res
+}
+
+func walkStructColumns(structTyp reflect.Type, parentIndex []int, key func(field reflect.StructField) string, res *[]structColumn) {
+	for i := 0; i < structTyp.NumField(); i++ {
+		ft := structTyp.Field(i)
+
+		index := make([]int, 0, len(parentIndex)+1)
+		index = append(index, parentIndex...)
+		index = append(index, i)
+
+		if ft.Type.Kind() == reflect.Struct && ft.Anonymous {
+			walkStructColumns(ft.Type, index, key, res)
+			continue
+		}
+		if !ft.IsExported() {
+			continue
+		}
+		title := key(ft)
+		if title == "" || title == "-" {
+			continue
+		}
+		*res = append(*res, structColumn{title: title, index: index})
+	}
+}
+
+// cellValue 转换为 excelize 能识别的类型,
+// 别名类型(如 type Status uint8)还原为基础类型, 以便数值写为数字
+func cellValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if !reflectUtils.HasVariants(v.Type()) {
+			return cellValue(v.Elem())
+		}
+		// 与 reflectUtils.ParseStrToInstance 对应, 写为带判别字段的 json
+		s, err := reflectUtils.FormatInstanceToStr(v)
+		if err != nil {
+			return v.Interface()
+		}
+		return s
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		switch val := v.Interface().(type) {
+		case time.Time, Image, Link:
+			// Image/Link 由 writeRows 写为图片/超链接
+			return val
+		}
+		// 与 reflectUtils.ParseStrToInstance 对应, 复合类型写为 json 或 MarshalText 的结果
+		s, err := reflectUtils.FormatInstanceToStr(v)
+		if err != nil {
+			return v.Interface()
+		}
+		return s
+	default:
+		return v.Interface()
+	}
+}
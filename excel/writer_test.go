@@ -0,0 +1,313 @@
+package excel
+
+import (
+	"archive/zip"
+	"github.com/JfL0unch/goUtil/reflectUtils"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type typSale struct {
+	Region string  `json:"region"`
+	Month  string  `json:"month"`
+	Sales  float64 `json:"sales"`
+	Status Status  `json:"status"`
+	secret string
+}
+
+var sales = []typSale{
+	{Region: "east", Month: "Jan", Sales: 10, Status: 1},
+	{Region: "west", Month: "Jan", Sales: 5, Status: 1},
+	{Region: "east", Month: "Feb", Sales: 7.5, Status: 2},
+	{Region: "east", Month: "Jan", Sales: 2, Status: 2},
+}
+
+func TestWriter_WriteStructs(t *testing.T) {
+	Convey("write structs", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "sales.xlsx")
+
+		w := NewWriter(WriterConfig{KeyFrom: KeyFromTag, KeyTagName: "json"})
+		So(w.WriteStructs("data", sales), ShouldBeNil)
+		So(w.WriteStructs("data", sales), ShouldNotBeNil)
+		So(w.SaveAs(excelFile), ShouldBeNil)
+
+		p := NewReader(ReaderConfig{SheetWithTitle: true, KeyFrom: KeyFromTag, KeyTagName: "json"})
+		retI, err := p.Parse(typSale{}, excelFile, "data")
+		So(err, ShouldBeNil)
+		So(p.sheet.Titles(), ShouldResemble, Titles{0: "region", 1: "month", 2: "sales", 3: "status"})
+
+		ret := retI.([]typSale)
+		So(ret, ShouldResemble, sales)
+	})
+
+	Convey("sheet save", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "sheet.xlsx")
+		sheet := Sheet{
+			titles: Titles{0: "id", 1: "name"},
+			rows:   [][]string{{"1", "jack"}, {"2", "tom"}},
+		}
+		So(sheet.Save(excelFile), ShouldBeNil)
+
+		got, err := Xuri{}.GetSheet(excelFile, "sheet1", FirstRowAsTitles())
+		So(err, ShouldBeNil)
+		So(got.Titles(), ShouldResemble, sheet.titles)
+		So(got.Rows(), ShouldResemble, sheet.rows)
+	})
+}
+
+func TestWriter_Pivot(t *testing.T) {
+	Convey("pivot", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "pivot.xlsx")
+
+		w := NewWriter(WriterConfig{KeyFrom: KeyFromTag, KeyTagName: "json"})
+		So(w.WriteStructs("data", sales), ShouldBeNil)
+
+		Convey("pivot table", func() {
+			err := w.AddPivotTable(PivotSpec{
+				DataSheet:  "data",
+				PivotSheet: "pivot",
+				Rows:       []string{"region"},
+				Columns:    []string{"month"},
+				Value:      "sales",
+			})
+			So(err, ShouldBeNil)
+			So(w.SaveAs(excelFile), ShouldBeNil)
+
+			f, err := excelize.OpenFile(excelFile)
+			So(err, ShouldBeNil)
+			defer f.Close()
+			So(f.GetSheetList(), ShouldResemble, []string{"data", "pivot"})
+		})
+
+		Convey("pivot table on existing sheet", func() {
+			spec := PivotSpec{DataSheet: "data", PivotSheet: "pivot", Rows: []string{"region"}, Value: "sales"}
+			So(w.AddPivotTable(spec), ShouldBeNil)
+			spec.Cell = "F1"
+			spec.Aggregate = PivotAverage
+			So(w.AddPivotTable(spec), ShouldBeNil)
+			So(w.SaveAs(excelFile), ShouldBeNil)
+
+			zr, err := zip.OpenReader(excelFile)
+			So(err, ShouldBeNil)
+			defer zr.Close()
+			tables := 0
+			for _, file := range zr.File {
+				if strings.HasPrefix(file.Name, "xl/pivotTables/pivotTable") && strings.HasSuffix(file.Name, ".xml") {
+					tables++
+				}
+			}
+			So(tables, ShouldEqual, 2)
+
+			f, err := excelize.OpenFile(excelFile)
+			So(err, ShouldBeNil)
+			defer f.Close()
+			So(f.GetSheetList(), ShouldResemble, []string{"data", "pivot"})
+		})
+
+		Convey("quoted sheet names", func() {
+			So(w.WriteStructs("Q1 '23", sales), ShouldBeNil)
+			err := w.AddPivotTable(PivotSpec{
+				DataSheet:  "Q1 '23",
+				PivotSheet: "pivot 1",
+				Rows:       []string{"region"},
+				Value:      "sales",
+			})
+			So(err, ShouldBeNil)
+			So(w.SaveAs(excelFile), ShouldBeNil)
+
+			So(sheetRef("Q1 '23"), ShouldEqual, "'Q1 ''23'")
+			ref, err := cellRange("a!b", 1, 1, 2, 3)
+			So(err, ShouldBeNil)
+			So(ref, ShouldEqual, "'a!b'!$A$1:$B$3")
+
+			So(w.WriteStructs("a!b", sales), ShouldBeNil)
+			So(w.AddPivotTable(PivotSpec{DataSheet: "a!b", PivotSheet: "p", Rows: []string{"region"}, Value: "sales"}), ShouldNotBeNil)
+		})
+
+		Convey("bad spec", func() {
+			err := w.AddPivotTable(PivotSpec{
+				DataSheet:  "data",
+				PivotSheet: "pivot",
+				Rows:       []string{"unknown"},
+				Value:      "sales",
+			})
+			So(err, ShouldNotBeNil)
+
+			err = w.AddSummarySheet(PivotSpec{
+				DataSheet:  "data",
+				PivotSheet: "summary",
+				Rows:       []string{"region"},
+				Value:      "sales",
+				Aggregate:  "Median",
+			})
+			So(err, ShouldNotBeNil)
+
+			// 汇总表的 sheet 必须不存在
+			So(w.WriteStructs("summary", sales), ShouldBeNil)
+			err = w.AddSummarySheet(PivotSpec{
+				DataSheet:  "data",
+				PivotSheet: "summary",
+				Rows:       []string{"region"},
+				Value:      "sales",
+			})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("summary sheet with columns", func() {
+			err := w.AddSummarySheet(PivotSpec{
+				DataSheet:  "data",
+				PivotSheet: "summary",
+				Rows:       []string{"region"},
+				Columns:    []string{"month"},
+				Value:      "sales",
+			})
+			So(err, ShouldBeNil)
+
+			rows, err := w.File().GetRows("summary")
+			So(err, ShouldBeNil)
+			So(rows, ShouldResemble, [][]string{
+				{"region", "Feb", "Jan"},
+				{"east", "7.5", "12"},
+				{"west", "", "5"},
+			})
+		})
+
+		Convey("summary sheet average", func() {
+			err := w.AddSummarySheet(PivotSpec{
+				DataSheet:  "data",
+				PivotSheet: "summary",
+				Rows:       []string{"region"},
+				Value:      "sales",
+				Aggregate:  PivotAverage,
+			})
+			So(err, ShouldBeNil)
+
+			rows, err := w.File().GetRows("summary")
+			So(err, ShouldBeNil)
+			So(rows, ShouldResemble, [][]string{
+				{"region", "Average of sales"},
+				{"east", "6.5"},
+				{"west", "5"},
+			})
+		})
+	})
+}
+
+func TestWriter_AddChart(t *testing.T) {
+	Convey("chart", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "chart.xlsx")
+
+		w := NewWriter(WriterConfig{KeyFrom: KeyFromTag, KeyTagName: "json"})
+		So(w.WriteStructs("data", sales), ShouldBeNil)
+
+		Convey("by title and field name", func() {
+			err := w.AddChart(ChartSpec{
+				DataSheet: "data",
+				Type:      ChartLine,
+				Title:     "sales",
+				Category:  "month",
+				Values:    []string{"sales", "Status"},
+			})
+			So(err, ShouldBeNil)
+
+			err = w.AddChart(ChartSpec{
+				DataSheet: "data",
+				Sheet:     "charts",
+				Cell:      "B2",
+				Type:      ChartBar,
+				Category:  "Region",
+				Values:    []string{"Sales"},
+			})
+			So(err, ShouldBeNil)
+			So(w.SaveAs(excelFile), ShouldBeNil)
+
+			f, err := excelize.OpenFile(excelFile)
+			So(err, ShouldBeNil)
+			defer f.Close()
+			So(f.GetSheetList(), ShouldResemble, []string{"data", "charts"})
+		})
+
+		Convey("quoted sheet name", func() {
+			So(w.WriteStructs("Q1 '23", sales), ShouldBeNil)
+			err := w.AddChart(ChartSpec{
+				DataSheet: "Q1 '23",
+				Sheet:     "charts",
+				Type:      ChartLine,
+				Category:  "month",
+				Values:    []string{"sales"},
+			})
+			So(err, ShouldBeNil)
+			So(w.SaveAs(excelFile), ShouldBeNil)
+
+			zr, err := zip.OpenReader(excelFile)
+			So(err, ShouldBeNil)
+			defer zr.Close()
+			rc, err := zr.Open("xl/charts/chart1.xml")
+			So(err, ShouldBeNil)
+			b, err := io.ReadAll(rc)
+			So(err, ShouldBeNil)
+			// xml 中 ' 转义为 &#39;
+			So(string(b), ShouldContainSubstring, "<f>&#39;Q1 &#39;&#39;23&#39;!$B$2:$B$5</f>")
+			So(string(b), ShouldContainSubstring, "<f>&#39;Q1 &#39;&#39;23&#39;!$C$1</f>")
+		})
+
+		Convey("bad spec", func() {
+			So(w.AddChart(ChartSpec{DataSheet: "data", Type: "radar", Category: "month", Values: []string{"sales"}}), ShouldNotBeNil)
+			So(w.AddChart(ChartSpec{DataSheet: "data", Type: ChartLine, Category: "month"}), ShouldNotBeNil)
+			So(w.AddChart(ChartSpec{DataSheet: "data", Type: ChartLine, Category: "month", Values: []string{"unknown"}}), ShouldNotBeNil)
+			So(w.AddChart(ChartSpec{DataSheet: "nodata", Type: ChartLine, Category: "month", Values: []string{"sales"}}), ShouldNotBeNil)
+		})
+	})
+}
+
+type payMethod interface {
+	Pay() string
+}
+
+type payCard struct {
+	No string `json:"no"`
+}
+
+func (c payCard) Pay() string { return "card " + c.No }
+
+type payWallet struct {
+	Account string `json:"account"`
+}
+
+func (w *payWallet) Pay() string { return "wallet " + w.Account }
+
+type typPayment struct {
+	Id      int       `json:"id"`
+	Payment payMethod `json:"payment"`
+}
+
+func TestWriter_Variant(t *testing.T) {
+	reflectUtils.RegisterVariant[payMethod]("card", payCard{})
+	reflectUtils.RegisterVariant[payMethod]("wallet", &payWallet{})
+
+	Convey("interface field", t, func() {
+		excelFile := filepath.Join(t.TempDir(), "payments.xlsx")
+		payments := []typPayment{
+			{Id: 1, Payment: payCard{No: "6222"}},
+			{Id: 2, Payment: &payWallet{Account: "tom"}},
+			{Id: 3},
+		}
+
+		w := NewWriter(WriterConfig{KeyFrom: KeyFromTag, KeyTagName: "json"})
+		So(w.WriteStructs("data", payments), ShouldBeNil)
+		So(w.SaveAs(excelFile), ShouldBeNil)
+
+		got, err := Xuri{}.GetSheet(excelFile, "data", FirstRowAsTitles())
+		So(err, ShouldBeNil)
+		So(got.Rows()[0][1], ShouldEqual, `{"type":"card","no":"6222"}`)
+
+		p := NewReader(ReaderConfig{SheetWithTitle: true, KeyFrom: KeyFromTag, KeyTagName: "json"})
+		retI, err := p.Parse(typPayment{}, excelFile, "data")
+		So(err, ShouldBeNil)
+		So(retI.([]typPayment), ShouldResemble, payments)
+	})
+}
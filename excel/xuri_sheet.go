@@ -2,6 +2,7 @@ package excel
 
 import (
 	"github.com/xuri/excelize/v2"
+	"os"
 )
 
 type Xuri struct{}
@@ -13,12 +14,13 @@ func (x Xuri) GetSheet(excelFile, sheetName string, opts ...Opt) (*Sheet, error)
 	for _, opt := range opts {
 		opt(parser)
 	}
-	f, err := excelize.OpenFile(excelFile)
+	f, size, err := x.openFile(excelFile, *parser)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	excelDatas, err := f.GetRows(sheetName)
+	excelDatas, err := x.readRows(f, sheetName, *parser, size)
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +29,7 @@ func (x Xuri) GetSheet(excelFile, sheetName string, opts ...Opt) (*Sheet, error)
 		return nil, nil
 	}
 
-	excel := Sheet{}
+	excel := Sheet{offset: parser.offset}
 	titles, err := parser.GetTitles(excelDatas)
 	if err != nil {
 		return nil, err
@@ -40,5 +42,146 @@ func (x Xuri) GetSheet(excelFile, sheetName string, opts ...Opt) (*Sheet, error)
 	}
 	excel.rows = rows
 
+	if parser.media != nil {
+		firstRow := 1 + parser.offset
+		if parser.WithTitle() {
+			firstRow++
+		}
+		if err := x.readMedia(parser.context(), f, sheetName, &excel, parser.media(titles), firstRow); err != nil {
+			return nil, err
+		}
+	}
+
 	return &excel, nil
 }
+
+// CountRows 统计数据行数(不含标题行), 只扫描行元素, 不解码单元格;
+// 行号不连续时按最后一行的行号计数, 与 GetRows 一致.
+// 与 GetRows 不同, 末尾存在于文件中但没有值的行(如只设置了格式)也会计入
+func (x Xuri) CountRows(excelFile, sheetName string, opts ...Opt) (int, error) {
+	parser := &Parser{}
+
+	for _, opt := range opts {
+		opt(parser)
+	}
+	f, _, err := x.openFile(excelFile, *parser)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	ctx := parser.context()
+	n := 0
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err := rows.Error(); err != nil {
+		return 0, err
+	}
+
+	if parser.WithTitle() && n > 0 {
+		n--
+	}
+	return n, nil
+}
+
+// readRows 按行流式读取 sheet,
+// 只保留标题行(若有)以及 [offset, offset+limit) 窗口内的数据行
+// fileSize 仅用于进度回调
+func (x Xuri) readRows(f *excelize.File, sheetName string, parser Parser, fileSize int64) ([][]string, error) {
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ctx := parser.context()
+	progress := Progress{
+		TotalRows:  estimateRows(f, sheetName, parser),
+		Bytes:      fileSize,
+		TotalBytes: fileSize,
+	}
+
+	results := make([][]string, 0, 64)
+	// dataIndex 当前数据行(不含标题行)的下标
+	dataIndex := 0
+	// max 窗口内最后一个非空行的位置, 用于去掉末尾空行
+	cur, max := 0, 0
+	titleRead := false
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if parser.WithTitle() && !titleRead {
+			titleRead = true
+			row, err := rows.Columns()
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, row)
+			cur++
+			if len(row) > 0 {
+				max = cur
+			}
+			continue
+		}
+
+		if dataIndex < parser.offset {
+			dataIndex++
+			continue
+		}
+		if parser.limit > 0 && dataIndex >= parser.offset+parser.limit {
+			break
+		}
+		dataIndex++
+
+		row, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+		cur++
+		if len(row) > 0 {
+			max = cur
+		}
+
+		progress.Rows++
+		parser.report(progress)
+	}
+	return results[:max], nil
+}
+
+// openFile 读取文件时统计字节数, 并响应 ctx 取消, 返回文件大小
+func (x Xuri) openFile(excelFile string, parser Parser) (*excelize.File, int64, error) {
+	file, err := os.Open(excelFile)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	r := &progressReader{
+		ctx:    parser.context(),
+		r:      file,
+		parser: parser,
+		total:  info.Size(),
+	}
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
@@ -0,0 +1,215 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Basic ParseBasic/FormatBasic 支持的类型, 不含别名类型(别名类型可能实现了 TextUnmarshaler 等接口)
+type Basic interface {
+	string | bool |
+		int | int8 | int16 | int32 | int64 |
+		uint | uint8 | uint16 | uint32 | uint64 | uintptr |
+		float32 | float64 |
+		time.Time | time.Duration
+}
+
+var defaultParseOptions = &ParseOptions{}
+
+func (o *ParseOptions) orDefault() *ParseOptions {
+	if o == nil {
+		return defaultParseOptions
+	}
+	return o
+}
+
+// ParseBasic 同 ParseStrToInstance, 但只有注册了解析函数时才使用反射, 供 excelgen 生成的代码使用.
+// o 为 nil 时使用默认选项
+func ParseBasic[T Basic](o *ParseOptions, strVal string) (T, error) {
+	o = o.orDefault()
+	var res T
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if v, ok, err := o.convert(typ, strVal); ok {
+		if err != nil {
+			return res, err
+		}
+		return v.Interface().(T), nil
+	}
+
+	var err error
+	switch p := interface{}(&res).(type) {
+	case *string:
+		*p = strVal
+	case *bool:
+		*p, err = o.parseBool(strVal)
+	case *int:
+		var i int64
+		i, err = o.basicInt(strVal, strconv.IntSize)
+		*p = int(i)
+	case *int8:
+		var i int64
+		i, err = o.basicInt(strVal, 8)
+		*p = int8(i)
+	case *int16:
+		var i int64
+		i, err = o.basicInt(strVal, 16)
+		*p = int16(i)
+	case *int32:
+		var i int64
+		i, err = o.basicInt(strVal, 32)
+		*p = int32(i)
+	case *int64:
+		*p, err = o.basicInt(strVal, 64)
+	case *uint:
+		var u uint64
+		u, err = o.basicUint(strVal, strconv.IntSize)
+		*p = uint(u)
+	case *uint8:
+		var u uint64
+		u, err = o.basicUint(strVal, 8)
+		*p = uint8(u)
+	case *uint16:
+		var u uint64
+		u, err = o.basicUint(strVal, 16)
+		*p = uint16(u)
+	case *uint32:
+		var u uint64
+		u, err = o.basicUint(strVal, 32)
+		*p = uint32(u)
+	case *uint64:
+		*p, err = o.basicUint(strVal, 64)
+	case *uintptr:
+		var u uint64
+		u, err = o.basicUint(strVal, strconv.IntSize)
+		*p = uintptr(u)
+	case *float32:
+		var f float64
+		f, err = o.basicFloat(strVal, 32)
+		*p = float32(f)
+	case *float64:
+		*p, err = o.basicFloat(strVal, 64)
+	case *time.Time:
+		*p, err = o.parseTime(strVal)
+	case *time.Duration:
+		*p, err = o.parseDuration(strVal)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res, nil
+}
+
+// basicInt/basicUint/basicFloat 与 getNumber 相同: 空字符串为 0, 错误带上原始值
+func (o *ParseOptions) basicInt(strVal string, bitSize int) (int64, error) {
+	if strVal == "" {
+		return 0, nil
+	}
+	i, err := o.parseInt(strVal, bitSize)
+	return i, errors.Wrapf(err, "val=%s", strVal)
+}
+
+func (o *ParseOptions) basicUint(strVal string, bitSize int) (uint64, error) {
+	if strVal == "" {
+		return 0, nil
+	}
+	u, err := o.parseUint(strVal, bitSize)
+	return u, errors.Wrapf(err, "val=%s", strVal)
+}
+
+func (o *ParseOptions) basicFloat(strVal string, bitSize int) (float64, error) {
+	if strVal == "" {
+		return 0, nil
+	}
+	f, err := o.parseFloat(strVal, bitSize)
+	return f, errors.Wrapf(err, "val=%s", strVal)
+}
+
+// ParseFieldAs 同 ParseField, 返回字段类型 T 的值, 供 excelgen 生成的代码解析非基本类型的字段.
+// o 为 nil 时使用默认选项
+func ParseFieldAs[T any](o *ParseOptions, f *FieldInfo, strVal string) (T, error) {
+	var res T
+	if f == nil {
+		return res, errors.Errorf("ParseFieldAs(%s): nil field", reflect.TypeOf((*T)(nil)).Elem())
+	}
+	v, err := o.orDefault().ParseField(f, strVal)
+	if err != nil {
+		return res, err
+	}
+	v = assignable(v, f.Field.Type)
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return res, nil
+	}
+	res, ok := v.Interface().(T)
+	if !ok {
+		return res, errors.Errorf("ParseFieldAs: field %s is %s, not %s", f.Path, v.Type(), reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return res, nil
+}
+
+// NewFormatOptions 生成可复用的选项
+func NewFormatOptions(opts ...FormatOpt) *FormatOptions {
+	return newFormatOptions(opts)
+}
+
+var defaultFormatOptions = newFormatOptions(nil)
+
+func (o *FormatOptions) orDefault() *FormatOptions {
+	if o == nil {
+		return defaultFormatOptions
+	}
+	return o
+}
+
+// FormatBasic 同 FormatInstanceToStr, 不使用反射, 供 excelgen 生成的代码使用. o 为 nil 时使用默认选项
+func FormatBasic[T Basic](o *FormatOptions, v T) string {
+	switch p := interface{}(&v).(type) {
+	case *string:
+		return *p
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *int:
+		return strconv.FormatInt(int64(*p), 10)
+	case *int8:
+		return strconv.FormatInt(int64(*p), 10)
+	case *int16:
+		return strconv.FormatInt(int64(*p), 10)
+	case *int32:
+		return strconv.FormatInt(int64(*p), 10)
+	case *int64:
+		return strconv.FormatInt(*p, 10)
+	case *uint:
+		return strconv.FormatUint(uint64(*p), 10)
+	case *uint8:
+		return strconv.FormatUint(uint64(*p), 10)
+	case *uint16:
+		return strconv.FormatUint(uint64(*p), 10)
+	case *uint32:
+		return strconv.FormatUint(uint64(*p), 10)
+	case *uint64:
+		return strconv.FormatUint(*p, 10)
+	case *uintptr:
+		return strconv.FormatUint(uint64(*p), 10)
+	case *float32:
+		return strconv.FormatFloat(float64(*p), 'f', -1, 32)
+	case *float64:
+		return strconv.FormatFloat(*p, 'f', -1, 64)
+	case *time.Time:
+		o = o.orDefault()
+		if p.IsZero() {
+			return ""
+		}
+		return p.In(o.timeLocation).Format(o.timeLayout)
+	case *time.Duration:
+		return p.String()
+	}
+	return ""
+}
+
+// FormatAs 同 FormatInstanceToStr, 按 v 的静态类型 T 格式化(接口类型保留判别字段),
+// 供 excelgen 生成的代码格式化非基本类型的字段. o 为 nil 时使用默认选项
+func FormatAs[T any](o *FormatOptions, v T) (string, error) {
+	return o.orDefault().format(reflect.ValueOf(&v).Elem())
+}
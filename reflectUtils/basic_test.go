@@ -0,0 +1,89 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sameAsReflect ParseBasic/FormatBasic 与 ParseStrToInstance/FormatInstanceToStr 的结果相同
+func sameAsReflect[T Basic](strVal string, opts ...ParseOpt) {
+	var zero T
+	want, wantErr := ParseStrToInstance(reflect.ValueOf(&zero).Elem(), strVal, opts...)
+	got, err := ParseBasic[T](NewParseOptions(opts...), strVal)
+	So(err != nil, ShouldEqual, wantErr != nil)
+	if err != nil {
+		return
+	}
+	So(got, ShouldResemble, want.Interface())
+
+	s, err := FormatInstanceToStr(reflect.ValueOf(got))
+	So(err, ShouldBeNil)
+	So(FormatBasic[T](nil, got), ShouldEqual, s)
+}
+
+func TestParseBasic(t *testing.T) {
+	Convey("same as reflect", t, func() {
+		for _, s := range []string{"", "0", "1", "-1", "300", "1.5", "1e3", "1,234", "0x1f", "x", "99999999999999999999"} {
+			sameAsReflect[int](s)
+			sameAsReflect[int8](s)
+			sameAsReflect[int8](s, WithStrict())
+			sameAsReflect[int64](s, WithScientific())
+			sameAsReflect[uint](s)
+			sameAsReflect[uint16](s, WithThousandSeparator(','))
+			sameAsReflect[uintptr](s, WithBasePrefix())
+			sameAsReflect[float32](s)
+			sameAsReflect[float64](s, WithStrict())
+			sameAsReflect[string](s)
+		}
+		for _, s := range []string{"", "yes", "否", "maybe"} {
+			sameAsReflect[bool](s)
+			sameAsReflect[bool](s, WithBoolValues([]string{"maybe"}, nil))
+		}
+		for _, s := range []string{"", "2023-08-07 00:34:00", "2023/8/7", "bad"} {
+			sameAsReflect[time.Time](s)
+			sameAsReflect[time.Time](s, WithStrict())
+		}
+		for _, s := range []string{"", "90", "1h30m", "1y"} {
+			sameAsReflect[time.Duration](s)
+			sameAsReflect[time.Duration](s, WithDurationUnit(time.Second))
+		}
+	})
+
+	Convey("converter", t, func() {
+		opts := []ParseOpt{WithConverter(reflect.TypeOf(""), func(s string) (interface{}, error) {
+			return strings.ToUpper(s), nil
+		})}
+		sameAsReflect[string]("abc", opts...)
+		got, err := ParseBasic[string](NewParseOptions(opts...), "abc")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "ABC")
+	})
+
+	Convey("field", t, func() {
+		type typX struct {
+			Level level    `json:"level"`
+			Tags  []string `json:"tags" sep:","`
+		}
+		fields := CachedFields(reflect.TypeOf(typX{}), "json")
+		f, _ := fields.ByPath("Level")
+		lv, err := ParseFieldAs[level](nil, f, "high")
+		So(err, ShouldBeNil)
+		So(lv, ShouldEqual, level(2))
+		s, err := FormatAs(nil, lv)
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "high")
+
+		f, _ = fields.ByPath("Tags")
+		tags, err := ParseFieldAs[[]string](nil, f, "a,b")
+		So(err, ShouldBeNil)
+		So(tags, ShouldResemble, []string{"a", "b"})
+
+		_, err = ParseFieldAs[int](nil, f, "a")
+		So(err, ShouldNotBeNil)
+		_, err = ParseFieldAs[int](nil, nil, "a")
+		So(err, ShouldNotBeNil)
+	})
+}
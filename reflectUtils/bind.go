@@ -0,0 +1,239 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FieldError 单个字段的绑定错误
+type FieldError struct {
+	// Field 字段名, 嵌入结构体的字段以 . 连接, 如 Base.Id
+	Field string
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return "field " + e.Field + "(" + e.Key + "=" + e.Value + "): " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors 所有字段的绑定错误, 按字段顺序排列
+type BindErrors []*FieldError
+
+func (e BindErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// structField 结构体(含打平的匿名结构体)中的一个字段
+type structField struct {
+	field reflect.StructField
+	// index 相对于最外层结构体的 FieldByIndex 路径
+	index []int
+	// path 字段名路径, 如 Base.Id
+	path string
+}
+
+// walkStructFields 按顺序遍历字段, Anonymous 的结构体字段被打平, 与 FlatStructFields 一致
+func walkStructFields(typ reflect.Type, index []int, path string, fn func(sf structField)) {
+	for i := 0; i < typ.NumField(); i++ {
+		ft := typ.Field(i)
+		idx := append(append(make([]int, 0, len(index)+1), index...), i)
+		p := ft.Name
+		if path != "" {
+			p = path + "." + ft.Name
+		}
+
+		if ft.Type.Kind() == reflect.Struct && ft.Anonymous {
+			walkStructFields(ft.Type, idx, p, fn)
+			continue
+		}
+		fn(structField{field: ft, index: idx, path: p})
+	}
+}
+
+// bindSource 字段 key 到字符串的来源
+type bindSource struct {
+	// key 字段对应的 key, ok=false 时跳过该字段
+	key func(ft reflect.StructField) (string, bool)
+	// lookup 不存在时 ok=false, 字段保持原值
+	lookup func(key string) ([]string, bool)
+	// multi slice 字段的每个值解析为一个元素
+	multi bool
+}
+
+// BindMap 把 m 中的值绑定到 dst(结构体指针)的字段,
+// key 为 tag 的名字(逗号前的部分), 没有 tag 时为字段名; tag 为 - 的字段被跳过.
+// m 中不存在的字段保持原值, 所有字段的错误以 BindErrors 一起返回
+func BindMap(dst interface{}, m map[string]string, tag string, opts ...ParseOpt) error {
+	return bind(dst, bindSource{
+		key: tagOrFieldName(tag),
+		lookup: func(key string) ([]string, bool) {
+			v, exist := m[key]
+			return []string{v}, exist
+		},
+	}, opts)
+}
+
+// BindValues 同 BindMap, slice 字段(不含 []byte)的每个值解析为一个元素,
+// 其它字段取第一个值
+func BindValues(dst interface{}, values url.Values, tag string, opts ...ParseOpt) error {
+	return bind(dst, bindSource{
+		key: tagOrFieldName(tag),
+		lookup: func(key string) ([]string, bool) {
+			v, exist := values[key]
+			return v, exist && len(v) > 0
+		},
+		multi: true,
+	}, opts)
+}
+
+// BindEnv 从环境变量绑定, 变量名为 prefix + `env` tag 的名字,
+// 没有 tag 时为字段名的大写下划线形式, 如 prefix=APP_ 时 DBHost 对应 APP_DB_HOST
+func BindEnv(dst interface{}, prefix string, opts ...ParseOpt) error {
+	return bind(dst, bindSource{
+		key: func(ft reflect.StructField) (string, bool) {
+			name, tagged, skip := tagName(ft, "env")
+			if skip {
+				return "", false
+			}
+			if !tagged {
+				name = upperSnake(ft.Name)
+			}
+			return prefix + name, true
+		},
+		lookup: func(key string) ([]string, bool) {
+			v, exist := os.LookupEnv(key)
+			return []string{v}, exist
+		},
+	}, opts)
+}
+
+// tagOrFieldName tag 的名字, 没有 tag 时为字段名
+func tagOrFieldName(tag string) func(ft reflect.StructField) (string, bool) {
+	return func(ft reflect.StructField) (string, bool) {
+		name, tagged, skip := tagName(ft, tag)
+		if skip {
+			return "", false
+		}
+		if !tagged {
+			name = ft.Name
+		}
+		return name, true
+	}
+}
+
+// tagName tag 中逗号前的名字, 没有 tag 或名字为空时 tagged=false; tag 为 - 时 skip=true
+func tagName(ft reflect.StructField, tag string) (name string, tagged bool, skip bool) {
+	if tag == "" {
+		return "", false, false
+	}
+	v, ok := ft.Tag.Lookup(tag)
+	if !ok {
+		return "", false, false
+	}
+	name = strings.Split(v, ",")[0]
+	if name == "-" {
+		return "", false, true
+	}
+	return name, name != "", false
+}
+
+func bind(dst interface{}, src bindSource, opts []ParseOpt) error {
+	dstVal := reflect.ValueOf(dst)
+	if dstVal.Kind() != reflect.Pointer || dstVal.IsNil() || dstVal.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("bind: dst must be a non-nil struct pointer, got %T", dst)
+	}
+	dstVal = dstVal.Elem()
+	o := newParseOptions(opts)
+
+	var errs BindErrors
+	for _, f := range CachedFields(dstVal.Type(), "").Fields {
+		key, ok := src.key(f.Field)
+		if !ok {
+			continue
+		}
+		values, exist := src.lookup(key)
+		if !exist {
+			continue
+		}
+
+		field := dstVal.FieldByIndex(f.Index)
+		if err := o.withSeps(f.seps, f.Field.Type).setField(field, values, src.multi); err != nil {
+			errs = append(errs, &FieldError{Field: f.Path, Key: key, Value: strings.Join(values, ","), Err: err})
+		}
+	}
+	if o.defaults {
+		if err := o.ApplyDefaults(dst); err != nil {
+			errs = append(errs, err.(BindErrors)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// setField multi=true 且字段为 slice 时逐个解析元素, 否则解析第一个值;
+// 注册了解析函数或实现了 Unmarshaler 的 slice 类型作为整体解析
+func (o *ParseOptions) setField(field reflect.Value, values []string, multi bool) error {
+	typ := field.Type()
+	_, converted := o.lookup(typ)
+	if multi && typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8 && !converted && !unmarshaler(typ) {
+		res := reflect.MakeSlice(typ, 0, len(values))
+		for i, s := range values {
+			v, err := o.parse(reflect.Zero(typ.Elem()), s)
+			if err != nil {
+				return errors.Wrapf(err, "[%d]", i)
+			}
+			res = reflect.Append(res, assignable(v, typ.Elem()))
+		}
+		field.Set(res)
+		return nil
+	}
+
+	v, err := o.parse(field, values[0])
+	if err != nil {
+		return err
+	}
+	field.Set(assignable(v, typ))
+	return nil
+}
+
+// assignable 别名类型等需要 Convert 的值
+func assignable(v reflect.Value, typ reflect.Type) reflect.Value {
+	if v.Type() != typ && v.Type().ConvertibleTo(typ) {
+		return v.Convert(typ)
+	}
+	return v
+}
+
+// upperSnake DBHost -> DB_HOST, userName -> USER_NAME
+func upperSnake(name string) string {
+	runes := []rune(name)
+	b := strings.Builder{}
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type bindBase struct {
+	Id      int       `json:"id" form:"id"`
+	Created time.Time `json:"created" form:"created"`
+}
+
+type bindUser struct {
+	bindBase
+	Name    string        `json:"name" form:"name"`
+	Age     uint8         `json:"age" form:"age"`
+	Admin   bool          `json:"admin" form:"admin"`
+	Tags    []string      `json:"tags" form:"tag"`
+	Scores  []int         `json:"scores" form:"score"`
+	Timeout time.Duration `json:"timeout" form:"timeout"`
+	Level   *level        `json:"level" form:"level"`
+	Ignored string        `json:"-" form:"-"`
+	secret  string
+}
+
+func TestBind(t *testing.T) {
+	Convey("BindMap", t, func() {
+		u := bindUser{Name: "keep"}
+		err := BindMap(&u, map[string]string{
+			"id":      "7",
+			"created": "2023-08-07 00:34:00",
+			"age":     "13",
+			"admin":   "yes",
+			"tags":    `["a","b"]`,
+			"timeout": "1m30s",
+			"level":   "high",
+			"Ignored": "x",
+		}, "json")
+		So(err, ShouldBeNil)
+		So(u.Id, ShouldEqual, 7)
+		So(u.Created, ShouldEqual, time.Date(2023, 8, 7, 0, 34, 0, 0, time.Local))
+		So(u.Name, ShouldEqual, "keep")
+		So(u.Age, ShouldEqual, 13)
+		So(u.Admin, ShouldBeTrue)
+		So(u.Tags, ShouldResemble, []string{"a", "b"})
+		So(u.Timeout, ShouldEqual, 90*time.Second)
+		So(*u.Level, ShouldEqual, level(2))
+		So(u.Ignored, ShouldEqual, "")
+
+		Convey("field name without tag", func() {
+			v := struct {
+				Name  string
+				Count int `json:",omitempty"`
+			}{}
+			So(BindMap(&v, map[string]string{"Name": "tom", "Count": "3"}, "json"), ShouldBeNil)
+			So(v.Name, ShouldEqual, "tom")
+			So(v.Count, ShouldEqual, 3)
+		})
+
+		Convey("all errors", func() {
+			u := bindUser{}
+			err := BindMap(&u, map[string]string{
+				"id":    "x",
+				"name":  "tom",
+				"age":   "300",
+				"admin": "maybe",
+			}, "json", WithStrict())
+			So(err, ShouldNotBeNil)
+
+			errs, ok := err.(BindErrors)
+			So(ok, ShouldBeTrue)
+			So(len(errs), ShouldEqual, 3)
+			So(errs[0].Field, ShouldEqual, "bindBase.Id")
+			So(errs[0].Key, ShouldEqual, "id")
+			So(errs[1].Field, ShouldEqual, "Age")
+			So(errors.Is(errs[1], ErrNumberOverflow), ShouldBeTrue)
+			So(errs[2].Value, ShouldEqual, "maybe")
+			So(errors.Is(errs[2], ErrBoolValue), ShouldBeTrue)
+			// 其它字段照常绑定
+			So(u.Name, ShouldEqual, "tom")
+		})
+
+		Convey("invalid dst", func() {
+			So(BindMap(bindUser{}, nil, "json"), ShouldNotBeNil)
+			So(BindMap((*bindUser)(nil), nil, "json"), ShouldNotBeNil)
+			i := 0
+			So(BindMap(&i, nil, "json"), ShouldNotBeNil)
+		})
+	})
+
+	Convey("BindValues", t, func() {
+		values, err := url.ParseQuery("id=3&name=tom&name=jack&tag=a&tag=b&score=1&score=2&score=3&admin=1")
+		So(err, ShouldBeNil)
+
+		u := bindUser{}
+		So(BindValues(&u, values, "form"), ShouldBeNil)
+		So(u.Id, ShouldEqual, 3)
+		So(u.Name, ShouldEqual, "tom")
+		So(u.Tags, ShouldResemble, []string{"a", "b"})
+		So(u.Scores, ShouldResemble, []int{1, 2, 3})
+		So(u.Admin, ShouldBeTrue)
+
+		values.Set("score", "x")
+		values.Add("score", "y")
+		err = BindValues(&u, values, "form")
+		errs, ok := err.(BindErrors)
+		So(ok, ShouldBeTrue)
+		So(len(errs), ShouldEqual, 1)
+		So(errs[0].Value, ShouldEqual, "x,y")
+	})
+
+	Convey("BindEnv", t, func() {
+		type config struct {
+			DBHost  string
+			DBPort  int
+			Debug   bool
+			Timeout time.Duration `env:"REQUEST_TIMEOUT"`
+			Skip    string        `env:"-"`
+		}
+		t.Setenv("APP_DB_HOST", "127.0.0.1")
+		t.Setenv("APP_DB_PORT", "3306")
+		t.Setenv("APP_DEBUG", "on")
+		t.Setenv("APP_REQUEST_TIMEOUT", "5s")
+		t.Setenv("APP_SKIP", "x")
+
+		c := config{}
+		So(BindEnv(&c, "APP_"), ShouldBeNil)
+		So(c, ShouldResemble, config{DBHost: "127.0.0.1", DBPort: 3306, Debug: true, Timeout: 5 * time.Second})
+
+		t.Setenv("APP_DB_PORT", "port")
+		err := BindEnv(&c, "APP_")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "APP_DB_PORT=port")
+	})
+
+	Convey("upperSnake", t, func() {
+		for in, want := range map[string]string{
+			"DBHost":   "DB_HOST",
+			"userName": "USER_NAME",
+			"ID":       "ID",
+			"HTTPPort": "HTTP_PORT",
+			"Port2FA":  "PORT2_FA",
+		} {
+			So(upperSnake(in), ShouldEqual, want)
+		}
+	})
+
+	Convey("FlatStructFields", t, func() {
+		fields := FlatStructFields(bindUser{})
+		names := make([]string, 0, len(fields))
+		for _, f := range fields {
+			names = append(names, f.Name)
+		}
+		So(names, ShouldResemble, []string{"Id", "Created", "Name", "Age", "Admin", "Tags", "Scores", "Timeout", "Level", "Ignored", "secret"})
+
+		// 结果来自缓存, 修改返回值不影响后续调用
+		fields[0].Name = "changed"
+		So(FlatStructFields(bindUser{})[0].Name, ShouldEqual, "Id")
+	})
+}
@@ -0,0 +1,48 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ErrBoolValue 字符串不在 bool 词表中
+var ErrBoolValue = errors.New("unknown bool value")
+
+// DefaultTrueValues/DefaultFalseValues 默认的 bool 词表, 比较时忽略大小写;
+// 空字符串解析为 false
+var (
+	DefaultTrueValues  = []string{"true", "t", "1", "yes", "y", "on", "是", "真", "对"}
+	DefaultFalseValues = []string{"false", "f", "0", "no", "n", "off", "否", "假", "错"}
+)
+
+// WithBoolValues 使用自定义的 bool 词表, 替换默认词表
+func WithBoolValues(trueValues, falseValues []string) ParseOpt {
+	return func(o *ParseOptions) {
+		o.trueValues = trueValues
+		o.falseValues = falseValues
+	}
+}
+
+// parseBool 不在词表中时返回 ErrBoolValue
+func (o *ParseOptions) parseBool(strVal string) (bool, error) {
+	s := strings.TrimSpace(strVal)
+	if s == "" {
+		return false, nil
+	}
+
+	trueValues, falseValues := o.trueValues, o.falseValues
+	if trueValues == nil && falseValues == nil {
+		trueValues, falseValues = DefaultTrueValues, DefaultFalseValues
+	}
+	for _, v := range trueValues {
+		if strings.EqualFold(s, v) {
+			return true, nil
+		}
+	}
+	for _, v := range falseValues {
+		if strings.EqualFold(s, v) {
+			return false, nil
+		}
+	}
+	return false, errors.Wrapf(ErrBoolValue, "val=%s", strVal)
+}
@@ -0,0 +1,52 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"testing"
+)
+
+func TestParseBool(t *testing.T) {
+	Convey("default vocabulary", t, func() {
+		for _, in := range []string{"true", "TRUE", "True", "t", "1", "yes", "Y", "on", "是", "真", " yes "} {
+			got, err := ParseStrToInstance(reflect.ValueOf(false), in)
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, true)
+		}
+		for _, in := range []string{"", "false", "FALSE", "f", "0", "no", "N", "off", "否", "假"} {
+			got, err := ParseStrToInstance(reflect.ValueOf(true), in)
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, false)
+		}
+
+		_, err := ParseStrToInstance(reflect.ValueOf(false), "maybe")
+		So(errors.Cause(err), ShouldEqual, ErrBoolValue)
+	})
+
+	Convey("custom vocabulary", t, func() {
+		opt := WithBoolValues([]string{"√"}, []string{"×"})
+		got, err := ParseStrToInstance(reflect.ValueOf(false), "√", opt)
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, true)
+
+		got, err = ParseStrToInstance(reflect.ValueOf(false), "×", opt)
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, false)
+
+		_, err = ParseStrToInstance(reflect.ValueOf(false), "true", opt)
+		So(errors.Cause(err), ShouldEqual, ErrBoolValue)
+	})
+
+	Convey("alias and pointer", t, func() {
+		type flag bool
+		got, err := ParseStrToInstance(reflect.ValueOf(flag(false)), "是")
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, flag(true))
+
+		var p *bool
+		got, err = ParseStrToInstance(reflect.ValueOf(p), "1")
+		So(err, ShouldBeNil)
+		So(*got.Interface().(*bool), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,186 @@
+package reflectUtils
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+// ErrMalformed slice/map/array 的字符串既不是合法的 json, 也不符合分隔符格式
+var ErrMalformed = errors.New("malformed collection")
+
+const (
+	// SepTag 字段级的分隔符: slice/array 的元素分隔符, 或 map 的键值对分隔符, 如 `sep:","`、`sep:";"`
+	SepTag = "sep"
+	// KVSepTag map 的 key 与 value 之间的分隔符, 默认 =, 如 `kvsep:":"`
+	KVSepTag = "kvsep"
+)
+
+// WithListSeparator slice/array 接受以 sep 分隔的字符串, 如 a,b,c; 合法的 json 数组仍按 json 解析
+func WithListSeparator(sep string) ParseOpt {
+	return func(o *ParseOptions) {
+		o.listSep = sep
+	}
+}
+
+// WithMapSeparators map 接受 k1=v1;k2=v2 形式的字符串(pairSep=";", kvSep="=");
+// 合法的 json 对象仍按 json 解析
+func WithMapSeparators(pairSep, kvSep string) ParseOpt {
+	return func(o *ParseOptions) {
+		o.pairSep, o.kvSep = pairSep, kvSep
+	}
+}
+
+// fieldSeps 字段 tag 中的分隔符
+type fieldSeps struct {
+	sep, kvSep       string
+	hasSep, hasKVSep bool
+}
+
+func fieldSepsOf(ft reflect.StructField) fieldSeps {
+	s := fieldSeps{}
+	s.sep, s.hasSep = ft.Tag.Lookup(SepTag)
+	s.kvSep, s.hasKVSep = ft.Tag.Lookup(KVSepTag)
+	return s
+}
+
+// withSeps 字段的 sep/kvsep tag 覆盖选项中的分隔符, 没有 tag 时返回 o
+func (o *ParseOptions) withSeps(s fieldSeps, typ reflect.Type) *ParseOptions {
+	if !s.hasSep && !s.hasKVSep {
+		return o
+	}
+	cp := *o
+	cp.unsepped = o.elemOptions()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Map {
+		if s.hasSep {
+			cp.pairSep = s.sep
+		}
+		if s.hasKVSep {
+			cp.kvSep = s.kvSep
+		}
+	} else if s.hasSep {
+		cp.listSep = s.sep
+	}
+	return &cp
+}
+
+// elemOptions 解析元素使用的选项: 字段的 sep/kvsep tag 只作用于最外层
+func (o *ParseOptions) elemOptions() *ParseOptions {
+	if o.unsepped != nil {
+		return o.unsepped
+	}
+	return o
+}
+
+// parseCollection slice/array/map: json, 或配置了分隔符时的分隔格式; 元素通过 ParseStrToInstance 的规则解析.
+// 空字符串和 null 为 nil(array 为零值);
+// 都不符合时返回 ErrMalformed; array 的元素个数必须与长度一致
+func (o *ParseOptions) parseCollection(zeroVal reflect.Value, strVal string) (reflect.Value, error) {
+	typ := zeroVal.Type()
+	trimmed := strings.TrimSpace(strVal)
+	if strVal == "" || trimmed == "null" {
+		return reflect.Zero(typ), nil
+	}
+
+	isJSON := (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) && json.Valid([]byte(trimmed))
+	isBytes := typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+	if isJSON || isBytes && strings.HasPrefix(trimmed, `"`) && json.Valid([]byte(trimmed)) {
+		return unmarshalCollection(typ, trimmed)
+	}
+
+	switch {
+	case typ.Kind() == reflect.Map && o.pairSep != "":
+		return o.splitMap(typ, strVal)
+	case typ.Kind() != reflect.Map && o.listSep != "":
+		return o.splitList(typ, strVal)
+	case isBytes:
+		// []byte 不是 json 时按原始字节
+		return reflect.ValueOf([]byte(strVal)).Convert(typ), nil
+	}
+	return reflect.Value{}, errors.Wrapf(ErrMalformed, "%s val=%s", typ, strVal)
+}
+
+func unmarshalCollection(typ reflect.Type, strVal string) (reflect.Value, error) {
+	if typ.Kind() != reflect.Array {
+		v, err := unmarshalJSON(typ, []byte(strVal))
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "val=%s", strVal)
+		}
+		return v, nil
+	}
+
+	// array 先解析为 slice 以检查长度
+	s, err := unmarshalJSON(reflect.SliceOf(typ.Elem()), []byte(strVal))
+	if err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "val=%s", strVal)
+	}
+	return toArray(typ, s, strVal)
+}
+
+func toArray(typ reflect.Type, s reflect.Value, strVal string) (reflect.Value, error) {
+	if s.Len() != typ.Len() {
+		return reflect.Value{}, errors.Wrapf(ErrMalformed, "%s needs %d elements, got %d, val=%s", typ, typ.Len(), s.Len(), strVal)
+	}
+	v := reflect.New(typ).Elem()
+	reflect.Copy(v, s)
+	return v, nil
+}
+
+func (o *ParseOptions) splitList(typ reflect.Type, strVal string) (reflect.Value, error) {
+	parts := strings.Split(strVal, o.listSep)
+	s := reflect.MakeSlice(reflect.SliceOf(typ.Elem()), len(parts), len(parts))
+	for i, part := range parts {
+		ev, err := o.elemOptions().parseElem(typ.Elem(), strings.TrimSpace(part))
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "[%d]", i)
+		}
+		s.Index(i).Set(ev)
+	}
+	if typ.Kind() == reflect.Array {
+		return toArray(typ, s, strVal)
+	}
+	return s.Convert(typ), nil
+}
+
+func (o *ParseOptions) splitMap(typ reflect.Type, strVal string) (reflect.Value, error) {
+	kvSep := o.kvSep
+	if kvSep == "" {
+		kvSep = "="
+	}
+	m := reflect.MakeMap(typ)
+	for _, pair := range strings.Split(strVal, o.pairSep) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, found := strings.Cut(pair, kvSep)
+		if !found {
+			return reflect.Value{}, errors.Wrapf(ErrMalformed, "%s pair %q has no %q", typ, pair, kvSep)
+		}
+		kv, err := o.elemOptions().parseElem(typ.Key(), strings.TrimSpace(k))
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "key %s", k)
+		}
+		vv, err := o.elemOptions().parseElem(typ.Elem(), strings.TrimSpace(v))
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "value of %s", k)
+		}
+		m.SetMapIndex(kv, vv)
+	}
+	return m, nil
+}
+
+// parseElem 元素为空 interface 时保留字符串
+func (o *ParseOptions) parseElem(typ reflect.Type, strVal string) (reflect.Value, error) {
+	if typ.Kind() == reflect.Interface && typ.NumMethod() == 0 {
+		return reflect.ValueOf(strVal), nil
+	}
+	v, err := o.parse(reflect.Zero(typ), strVal)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return assignable(v, typ), nil
+}
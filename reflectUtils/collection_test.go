@@ -0,0 +1,130 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"testing"
+)
+
+func TestParseCollection(t *testing.T) {
+	Convey("separated", t, func() {
+		v, err := ParseStrToInstance(reflect.ValueOf([]string{}), "a, b,c", WithListSeparator(","))
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, []string{"a", "b", "c"})
+
+		v, err = ParseStrToInstance(reflect.ValueOf([]int{}), "1|2|3", WithListSeparator("|"))
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, []int{1, 2, 3})
+
+		v, err = ParseStrToInstance(reflect.ValueOf([]level{}), "low,high", WithListSeparator(","))
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, []level{1, 2})
+
+		v, err = ParseStrToInstance(reflect.ValueOf(map[string]int{}), "a=1;b=2;", WithMapSeparators(";", "="))
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, map[string]int{"a": 1, "b": 2})
+
+		v, err = ParseStrToInstance(reflect.ValueOf([2]int{}), "1,2", WithListSeparator(","))
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, [2]int{1, 2})
+
+		// json 仍然可用
+		v, err = ParseStrToInstance(reflect.ValueOf([]int{}), "[1,2]", WithListSeparator(","))
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, []int{1, 2})
+
+		v, err = ParseStrToInstance(reflect.ValueOf([]byte{}), "abc")
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, []byte("abc"))
+	})
+
+	Convey("null", t, func() {
+		for _, zero := range []interface{}{[]int{}, map[string]int{}, []byte{}, [2]int{}} {
+			v, err := ParseStrToInstance(reflect.ValueOf(zero), "null")
+			So(err, ShouldBeNil)
+			So(v.IsZero(), ShouldBeTrue)
+		}
+		v, err := ParseStrToInstance(reflect.ValueOf([]int{}), " null ", WithListSeparator(","))
+		So(err, ShouldBeNil)
+		So(v.IsNil(), ShouldBeTrue)
+	})
+
+	Convey("malformed", t, func() {
+		_, err := ParseStrToInstance(reflect.ValueOf([]int{}), "1,2")
+		So(errors.Is(err, ErrMalformed), ShouldBeTrue)
+
+		_, err = ParseStrToInstance(reflect.ValueOf([]int{}), "[1,2")
+		So(errors.Is(err, ErrMalformed), ShouldBeTrue)
+
+		_, err = ParseStrToInstance(reflect.ValueOf([]int{}), "1,x", WithListSeparator(","))
+		So(err, ShouldNotBeNil)
+
+		_, err = ParseStrToInstance(reflect.ValueOf([2]int{}), "1,2,3", WithListSeparator(","))
+		So(errors.Is(err, ErrMalformed), ShouldBeTrue)
+
+		_, err = ParseStrToInstance(reflect.ValueOf([2]int{}), "[1]")
+		So(errors.Is(err, ErrMalformed), ShouldBeTrue)
+
+		_, err = ParseStrToInstance(reflect.ValueOf(map[string]int{}), "a=1;b", WithMapSeparators(";", "="))
+		So(errors.Is(err, ErrMalformed), ShouldBeTrue)
+
+		_, err = ParseStrToInstance(reflect.ValueOf(map[string]int{}), `["a"]`)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("tag", t, func() {
+		type typX struct {
+			Tags   []string          `form:"tags" sep:","`
+			Ids    [3]int            `form:"ids" sep:" "`
+			Attrs  map[string]string `form:"attrs" sep:";" kvsep:":"`
+			Scores map[string]int    `form:"scores"`
+		}
+		var x typX
+		err := BindMap(&x, map[string]string{
+			"tags":   "a,b",
+			"ids":    "1 2 3",
+			"attrs":  "color:red;size:L",
+			"scores": "math=90,art=80",
+		}, "form", WithMapSeparators(",", "="))
+		So(err, ShouldBeNil)
+		So(x.Tags, ShouldResemble, []string{"a", "b"})
+		So(x.Ids, ShouldResemble, [3]int{1, 2, 3})
+		So(x.Attrs, ShouldResemble, map[string]string{"color": "red", "size": "L"})
+		So(x.Scores, ShouldResemble, map[string]int{"math": 90, "art": 80})
+
+		f, _ := CachedFields(reflect.TypeOf(x), "form").ByName("tags")
+		v, err := NewParseOptions().ParseField(f, "x,y")
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, []string{"x", "y"})
+
+		err = BindMap(&x, map[string]string{"ids": "1 2"}, "form")
+		errs, ok := err.(BindErrors)
+		So(ok, ShouldBeTrue)
+		So(errors.Is(errs[0], ErrMalformed), ShouldBeTrue)
+	})
+
+	Convey("tag only applies to the outer level", t, func() {
+		type typY struct {
+			Grid [][]int             `form:"grid" sep:";"`
+			Ms   map[string][]string `form:"ms" sep:";" kvsep:":"`
+		}
+		var y typY
+		err := BindMap(&y, map[string]string{
+			"grid": "[1];[2,3]",
+			"ms":   `a:["x","y"];b:["z"]`,
+		}, "form")
+		So(err, ShouldBeNil)
+		So(y.Grid, ShouldResemble, [][]int{{1}, {2, 3}})
+		So(y.Ms, ShouldResemble, map[string][]string{"a": {"x", "y"}, "b": {"z"}})
+
+		// 内层使用选项中的分隔符
+		y = typY{}
+		err = BindMap(&y, map[string]string{"grid": "1,2;3"}, "form", WithListSeparator(","))
+		So(err, ShouldBeNil)
+		So(y.Grid, ShouldResemble, [][]int{{1, 2}, {3}})
+
+		err = BindMap(&y, map[string]string{"grid": "1;2"}, "form")
+		So(err, ShouldNotBeNil)
+	})
+}
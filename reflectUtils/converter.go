@@ -0,0 +1,200 @@
+package reflectUtils
+
+import (
+	"encoding"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ConvertFunc 把字符串解析为注册类型的值,
+// 返回值需要是该类型或可以 Convert 为该类型, nil 表示零值
+type ConvertFunc func(strVal string) (interface{}, error)
+
+// Converters reflect.Type 到 ConvertFunc 的注册表, 并发安全
+type Converters struct {
+	mu sync.RWMutex
+	m  map[reflect.Type]ConvertFunc
+}
+
+func NewConverters() *Converters {
+	return &Converters{
+		m: make(map[reflect.Type]ConvertFunc, 0),
+	}
+}
+
+// Register 注册 typ 的解析函数, 已存在时覆盖
+func (c *Converters) Register(typ reflect.Type, fn ConvertFunc) *Converters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[typ] = fn
+	return c
+}
+
+func (c *Converters) Lookup(typ reflect.Type) (ConvertFunc, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fn, exist := c.m[typ]
+	return fn, exist
+}
+
+// defaultConverters 全局注册表, 所有 ParseStrToInstance 调用都会使用
+var defaultConverters = NewConverters()
+
+// RegisterConverter 在全局注册表中注册 typ 的解析函数, 例如:
+//
+//	reflectUtils.RegisterConverter(reflect.TypeOf(net.IP{}), func(s string) (interface{}, error) {
+//		return net.ParseIP(s), nil
+//	})
+func RegisterConverter(typ reflect.Type, fn ConvertFunc) {
+	defaultConverters.Register(typ, fn)
+	resetFieldCache()
+}
+
+// ConverterOf 由类型安全的函数生成 ConvertFunc 以及对应的 reflect.Type
+func ConverterOf[T any](fn func(strVal string) (T, error)) (reflect.Type, ConvertFunc) {
+	return reflect.TypeOf((*T)(nil)).Elem(), func(strVal string) (interface{}, error) {
+		return fn(strVal)
+	}
+}
+
+// ParseOptions ParseStrToInstance 的选项
+type ParseOptions struct {
+	// converters 单次调用的注册表, 优先于全局注册表
+	converters *Converters
+
+	strict      bool
+	thousandSep rune
+	underscores bool
+	basePrefix  bool
+	scientific  bool
+
+	// timeParser 为空时使用零值 TimeParser
+	timeParser *TimeParser
+	// durationUnit 不带单位的整数 time.Duration 的单位, 为 0 时不接受不带单位的整数
+	durationUnit time.Duration
+
+	// trueValues/falseValues 都为空时使用默认词表
+	trueValues  []string
+	falseValues []string
+
+	// defaults 绑定后填充 default tag
+	defaults bool
+
+	// listSep slice/array 的元素分隔符, pairSep/kvSep map 的分隔符, 为空时只接受 json
+	listSep string
+	pairSep string
+	kvSep   string
+	// unsepped 字段的 sep/kvsep tag 覆盖分隔符之前的选项, 解析嵌套的元素时使用
+	unsepped *ParseOptions
+}
+
+type ParseOpt func(o *ParseOptions)
+
+// WithConverters 使用 c 中注册的解析函数, 优先于全局注册表
+func WithConverters(c *Converters) ParseOpt {
+	return func(o *ParseOptions) {
+		o.converters = c
+	}
+}
+
+// WithConverter 只对本次调用注册 typ 的解析函数
+func WithConverter(typ reflect.Type, fn ConvertFunc) ParseOpt {
+	return func(o *ParseOptions) {
+		if o.converters == nil {
+			o.converters = NewConverters()
+		}
+		o.converters.Register(typ, fn)
+	}
+}
+
+func newParseOptions(opts []ParseOpt) *ParseOptions {
+	o := &ParseOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// lookup 先查单次调用的注册表, 再查全局注册表
+func (o *ParseOptions) lookup(typ reflect.Type) (ConvertFunc, bool) {
+	if fn, exist := o.converters.Lookup(typ); exist {
+		return fn, true
+	}
+	return defaultConverters.Lookup(typ)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// convert 使用注册的解析函数, 没有注册时 ok=false
+func (o *ParseOptions) convert(typ reflect.Type, strVal string) (v reflect.Value, ok bool, err error) {
+	fn, exist := o.lookup(typ)
+	if !exist {
+		return reflect.Value{}, false, nil
+	}
+	v, err = convertWith(fn, typ, strVal)
+	return v, true, err
+}
+
+func convertWith(fn ConvertFunc, typ reflect.Type, strVal string) (reflect.Value, error) {
+	got, err := fn(strVal)
+	if err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "convert %s val=%s", typ, strVal)
+	}
+	gotVal := reflect.ValueOf(got)
+	switch {
+	case !gotVal.IsValid():
+		return reflect.Zero(typ), nil
+	case gotVal.Type() == typ:
+		return gotVal, nil
+	case gotVal.Type().ConvertibleTo(typ):
+		return gotVal.Convert(typ), nil
+	default:
+		return reflect.Value{}, errors.Errorf("convert %s val=%s: got %s", typ, strVal, gotVal.Type())
+	}
+}
+
+// unmarshaler *typ 是否实现了 encoding.TextUnmarshaler 或 json.Unmarshaler,
+// 指针类型由 getInstance 解引用后再判断; time.Time 的 UnmarshalText 只接受 RFC3339, 由 TimeParser 处理
+func unmarshaler(typ reflect.Type) bool {
+	if typ == timeType || typ.Kind() == reflect.Pointer {
+		return false
+	}
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(jsonUnmarshalerType)
+}
+
+// unmarshal 通过 UnmarshalText(优先)或 UnmarshalJSON 解析,
+// UnmarshalJSON 的输入不是合法 json 时作为 json 字符串传入
+func unmarshal(typ reflect.Type, strVal string) (reflect.Value, error) {
+	v := reflect.New(typ)
+	if strVal == "" {
+		return v.Elem(), nil
+	}
+
+	switch u := v.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		if err := u.UnmarshalText([]byte(strVal)); err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "UnmarshalText val=%s", strVal)
+		}
+	case json.Unmarshaler:
+		data := []byte(strVal)
+		if !json.Valid(data) {
+			data, _ = json.Marshal(strVal)
+		}
+		if err := u.UnmarshalJSON(data); err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "UnmarshalJSON val=%s", strVal)
+		}
+	}
+	return v.Elem(), nil
+}
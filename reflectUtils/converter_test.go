@@ -0,0 +1,132 @@
+package reflectUtils
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", text)
+	}
+	return nil
+}
+
+type color struct {
+	Name string
+}
+
+func (c *color) UnmarshalJSON(data []byte) error {
+	c.Name = strings.ToUpper(strings.Trim(string(data), `"`))
+	return nil
+}
+
+type cents int64
+
+func TestConverters(t *testing.T) {
+	Convey("converters", t, func() {
+		Convey("per call", func() {
+			typ, fn := ConverterOf(func(s string) (net.IP, error) {
+				ip := net.ParseIP(s)
+				if ip == nil {
+					return nil, fmt.Errorf("invalid ip %s", s)
+				}
+				return ip, nil
+			})
+
+			got, err := ParseStrToInstance(reflect.ValueOf(net.IP{}), "10.0.0.1", WithConverter(typ, fn))
+			So(err, ShouldBeNil)
+			So(got.Interface().(net.IP).String(), ShouldEqual, "10.0.0.1")
+
+			_, err = ParseStrToInstance(reflect.ValueOf(net.IP{}), "x", WithConverter(typ, fn))
+			So(err, ShouldNotBeNil)
+
+			var ip *net.IP
+			got, err = ParseStrToInstance(reflect.ValueOf(ip), "10.0.0.2", WithConverter(typ, fn))
+			So(err, ShouldBeNil)
+			So(got.Interface().(*net.IP).String(), ShouldEqual, "10.0.0.2")
+		})
+
+		Convey("before kind switch", func() {
+			c := NewConverters().Register(reflect.TypeOf(cents(0)), func(s string) (interface{}, error) {
+				var yuan, fen int64
+				if _, err := fmt.Sscanf(s, "%d.%d", &yuan, &fen); err != nil {
+					return nil, err
+				}
+				return yuan*100 + fen, nil
+			})
+
+			got, err := ParseStrToInstance(reflect.ValueOf(cents(0)), "12.34", WithConverters(c))
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, cents(1234))
+
+			// 没有注册时按 Kind 解析
+			_, err = ParseStrToInstance(reflect.ValueOf(cents(0)), "12.34")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("global", func() {
+			type code string
+			RegisterConverter(reflect.TypeOf(code("")), func(s string) (interface{}, error) {
+				return strings.ToUpper(s), nil
+			})
+
+			got, err := ParseStrToInstance(reflect.ValueOf(code("")), "ab")
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, code("AB"))
+
+			// 单次调用的注册优先
+			got, err = ParseStrToInstance(reflect.ValueOf(code("")), "ab", WithConverter(reflect.TypeOf(code("")), func(s string) (interface{}, error) {
+				return code("x" + s), nil
+			}))
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, code("xab"))
+		})
+
+		Convey("wrong result type", func() {
+			_, err := ParseStrToInstance(reflect.ValueOf(0), "1", WithConverter(reflect.TypeOf(0), func(s string) (interface{}, error) {
+				return []string{s}, nil
+			}))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("TextUnmarshaler", func() {
+			got, err := ParseStrToInstance(reflect.ValueOf(level(0)), "high")
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, level(2))
+
+			var l *level
+			got, err = ParseStrToInstance(reflect.ValueOf(l), "low")
+			So(err, ShouldBeNil)
+			So(*got.Interface().(*level), ShouldEqual, level(1))
+
+			got, err = ParseStrToInstance(reflect.ValueOf(level(0)), "")
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, level(0))
+
+			_, err = ParseStrToInstance(reflect.ValueOf(level(0)), "mid")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("json.Unmarshaler", func() {
+			got, err := ParseStrToInstance(reflect.ValueOf(color{}), "red")
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldResemble, color{Name: "RED"})
+
+			got, err = ParseStrToInstance(reflect.ValueOf(color{}), `"blue"`)
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldResemble, color{Name: "BLUE"})
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+)
+
+// DefaultTag 默认值使用的 tag
+const DefaultTag = "default"
+
+// WithDefaults BindMap/BindValues/BindEnv/Unflatten(通过 WithPathParseOpts) 绑定后, 对仍为零值的字段调用 ApplyDefaults
+func WithDefaults() ParseOpt {
+	return func(o *ParseOptions) {
+		o.defaults = true
+	}
+}
+
+// ApplyDefaults 把 `default:"..."` tag 的值用 ParseStrToInstance 解析后填入 ptr(结构体指针)中为零值的字段,
+// 如 `default:"10"`、`default:"2023-08-07"`、`default:"[\"a\",\"b\"]"`;
+// 嵌套的结构体、非 nil 的结构体指针以及 slice/array 中的结构体会递归处理.
+// 是否填充只看字段是否为零值, 显式设置的 0、false、"" 同样会被默认值覆盖.
+// 所有字段的错误以 BindErrors 一起返回
+func ApplyDefaults(ptr interface{}, opts ...ParseOpt) error {
+	return newParseOptions(opts).ApplyDefaults(ptr)
+}
+
+// ApplyDefaults 使用 o 解析默认值, 批量处理时可复用 o
+func (o *ParseOptions) ApplyDefaults(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("ApplyDefaults: ptr must be a non-nil struct pointer, got %T", ptr)
+	}
+	d := &defaulter{o: o, visited: map[uintptr]bool{v.Pointer(): true}}
+	d.structFields(v.Elem(), "")
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+	return nil
+}
+
+type defaulter struct {
+	o *ParseOptions
+	// visited 已处理的结构体指针, 避免环
+	visited map[uintptr]bool
+	errs    BindErrors
+}
+
+func (d *defaulter) structFields(v reflect.Value, path string) {
+	for _, f := range CachedFields(v.Type(), "").Fields {
+		field := v.FieldByIndex(f.Index)
+		p := joinPath(path, f.Path)
+		if def, ok := f.Field.Tag.Lookup(DefaultTag); ok && field.IsZero() {
+			nv, err := d.o.withSeps(f.seps, field.Type()).parse(reflect.Zero(field.Type()), def)
+			if err != nil {
+				d.errs = append(d.errs, &FieldError{Field: p, Key: DefaultTag, Value: def, Err: err})
+				continue
+			}
+			field.Set(assignable(nv, field.Type()))
+		}
+		d.nested(field, p)
+	}
+}
+
+// nested 进入嵌套的结构体
+func (d *defaulter) nested(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct || d.visited[v.Pointer()] {
+			return
+		}
+		d.visited[v.Pointer()] = true
+		d.nested(v.Elem(), path)
+	case reflect.Struct:
+		if !isLeafType(v.Type()) {
+			d.structFields(v, path)
+		}
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem().Kind() {
+		case reflect.Struct, reflect.Pointer:
+			for i := 0; i < v.Len(); i++ {
+				d.nested(v.Index(i), path+"["+strconv.Itoa(i)+"]")
+			}
+		}
+	}
+}
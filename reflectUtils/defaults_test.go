@@ -0,0 +1,99 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type defaultLimit struct {
+	Max  int           `default:"100"`
+	Wait time.Duration `default:"1m"`
+}
+
+type defaultConfig struct {
+	Host    string            `json:"host" default:"localhost"`
+	Port    uint16            `json:"port" default:"8080"`
+	Debug   bool              `json:"debug" default:"yes"`
+	Ratio   float64           `json:"ratio" default:"0.5"`
+	Start   time.Time         `json:"start" default:"2023-08-07"`
+	Tags    []string          `json:"tags" default:"[\"a\",\"b\"]"`
+	Labels  map[string]string `json:"labels" default:"{\"env\":\"dev\"}"`
+	Level   *level            `json:"level" default:"high"`
+	Limit   defaultLimit      `json:"limit"`
+	Backup  *defaultLimit     `json:"backup"`
+	Extra   *defaultLimit     `json:"extra" default:"{\"Max\":1}"`
+	Workers []defaultLimit    `json:"workers"`
+	Note    string            `json:"note"`
+	Next    *defaultConfig    `json:"next"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	Convey("ApplyDefaults", t, func() {
+		c := defaultConfig{
+			Port:    9090,
+			Backup:  &defaultLimit{Max: 5},
+			Workers: []defaultLimit{{}, {Wait: time.Second}},
+		}
+		c.Next = &c
+		So(ApplyDefaults(&c), ShouldBeNil)
+
+		So(c.Host, ShouldEqual, "localhost")
+		So(c.Port, ShouldEqual, 9090)
+		So(c.Debug, ShouldBeTrue)
+		So(c.Ratio, ShouldEqual, 0.5)
+		So(c.Start, ShouldEqual, time.Date(2023, 8, 7, 0, 0, 0, 0, time.Local))
+		So(c.Tags, ShouldResemble, []string{"a", "b"})
+		So(c.Labels, ShouldResemble, map[string]string{"env": "dev"})
+		So(*c.Level, ShouldEqual, level(2))
+		So(c.Limit, ShouldResemble, defaultLimit{Max: 100, Wait: time.Minute})
+		So(*c.Backup, ShouldResemble, defaultLimit{Max: 5, Wait: time.Minute})
+		So(*c.Extra, ShouldResemble, defaultLimit{Max: 1, Wait: time.Minute})
+		So(c.Workers, ShouldResemble, []defaultLimit{{Max: 100, Wait: time.Minute}, {Max: 100, Wait: time.Second}})
+		So(c.Note, ShouldEqual, "")
+
+		Convey("errors", func() {
+			v := struct {
+				A int `default:"x"`
+				B int `default:"3"`
+			}{}
+			err := ApplyDefaults(&v)
+			errs, ok := err.(BindErrors)
+			So(ok, ShouldBeTrue)
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Field, ShouldEqual, "A")
+			So(v.B, ShouldEqual, 3)
+
+			So(ApplyDefaults(v), ShouldNotBeNil)
+		})
+
+		Convey("options", func() {
+			v := struct {
+				N int `default:"1,000"`
+			}{}
+			So(ApplyDefaults(&v, WithThousandSeparator(',')), ShouldBeNil)
+			So(v.N, ShouldEqual, 1000)
+		})
+	})
+
+	Convey("binders", t, func() {
+		var c defaultConfig
+		So(BindMap(&c, map[string]string{"port": "1"}, "json", WithDefaults()), ShouldBeNil)
+		So(c.Host, ShouldEqual, "localhost")
+		So(c.Port, ShouldEqual, 1)
+
+		c = defaultConfig{}
+		So(BindValues(&c, url.Values{"host": {"h"}}, "json", WithDefaults()), ShouldBeNil)
+		So(c.Host, ShouldEqual, "h")
+		So(c.Port, ShouldEqual, 8080)
+
+		c = defaultConfig{}
+		So(Unflatten(&c, map[string]string{"limit.Max": "7"}, "json", WithPathParseOpts(WithDefaults())), ShouldBeNil)
+		So(c.Limit, ShouldResemble, defaultLimit{Max: 7, Wait: time.Minute})
+
+		c = defaultConfig{}
+		So(BindMap(&c, nil, "json"), ShouldBeNil)
+		So(c.Host, ShouldEqual, "")
+	})
+}
@@ -0,0 +1,408 @@
+package reflectUtils
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ChangeType 变化的类型
+type ChangeType int
+
+const (
+	// ChangeUpdate 值被修改
+	ChangeUpdate ChangeType = iota
+	// ChangeCreate slice 末尾新增的元素或新增的 map key
+	ChangeCreate
+	// ChangeDelete slice 末尾删除的元素或删除的 map key
+	ChangeDelete
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeCreate:
+		return "create"
+	case ChangeDelete:
+		return "delete"
+	default:
+		return "update"
+	}
+}
+
+// Change 一处变化, Path 为 Get/Set 使用的路径(字段名为 Go 字段名), 整体替换时为空字符串
+type Change struct {
+	Path string
+	Type ChangeType
+	Old  interface{}
+	New  interface{}
+}
+
+// DiffOptions Diff 的选项
+type DiffOptions struct {
+	nilEqualsEmpty bool
+}
+
+// DiffOpt Diff 的选项
+type DiffOpt func(o *DiffOptions)
+
+// WithNilEqualsEmpty nil 和空的 slice/map 视为相等, 默认不相等
+func WithNilEqualsEmpty() DiffOpt {
+	return func(o *DiffOptions) {
+		o.nilEqualsEmpty = true
+	}
+}
+
+// visit 已比较或已复制的指针/map, 用于处理环
+type visit struct {
+	a, b uintptr
+	typ  reflect.Type
+}
+
+// DeepCopy 深拷贝 src, 共享的指针和环在副本中保持相同的结构.
+// 未导出字段、time.Time 等实现了 Marshaler 的类型浅拷贝, chan/func 保持原值
+func DeepCopy[T any](src T) T {
+	var dst T
+	reflect.ValueOf(&dst).Elem().Set(deepCopy(reflect.ValueOf(&src).Elem(), map[visit]reflect.Value{}))
+	return dst
+}
+
+func deepCopy(v reflect.Value, copied map[visit]reflect.Value) reflect.Value {
+	typ := v.Type()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{a: v.Pointer(), typ: typ}
+		if c, ok := copied[key]; ok {
+			return c
+		}
+		c := reflect.New(typ.Elem())
+		copied[key] = c
+		c.Elem().Set(deepCopy(v.Elem(), copied))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(typ).Elem()
+		c.Set(deepCopy(v.Elem(), copied))
+		return c
+	case reflect.Struct:
+		c := reflect.New(typ).Elem()
+		c.Set(v)
+		if isLeafType(typ) {
+			return c
+		}
+		walkStructFields(typ, nil, "", func(sf structField) {
+			if sf.field.IsExported() {
+				c.FieldByIndex(sf.index).Set(deepCopy(v.FieldByIndex(sf.index), copied))
+			}
+		})
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(typ, v.Len(), v.Len())
+		if typ.Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(c, v)
+			return c
+		}
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copied))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(typ).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), copied))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := visit{a: v.Pointer(), typ: typ}
+		if c, ok := copied[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(typ, v.Len())
+		copied[key] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopy(iter.Key(), copied), deepCopy(iter.Value(), copied))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// Diff 比较 a 和 b, 返回从 a 变为 b 的所有变化:
+// time.Time 按 Equal 比较, 未导出字段被忽略, 指针按指向的值比较且可以有环;
+// slice 逐个元素比较, 长度不同时多出的元素为 ChangeCreate/ChangeDelete;
+// map 的 key 按字符串排序, 含有 . [ ] " 的 key 在路径中为 ["..."] 的形式; 类型不同时整体替换
+func Diff(a, b interface{}, opts ...DiffOpt) []Change {
+	o := &DiffOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	d := &differ{opts: o, visited: map[visit]bool{}}
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.changes
+}
+
+type differ struct {
+	opts    *DiffOptions
+	visited map[visit]bool
+	changes []Change
+}
+
+func (d *differ) add(path string, typ ChangeType, a, b reflect.Value) {
+	d.changes = append(d.changes, Change{Path: path, Type: typ, Old: valueOf(a), New: valueOf(b)})
+}
+
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.add(path, ChangeUpdate, a, b)
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		d.add(path, ChangeUpdate, a, b)
+		return
+	}
+
+	typ := a.Type()
+	if isLeafType(typ) {
+		if !d.leafEqual(a, b) {
+			d.add(path, ChangeUpdate, a, b)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, ChangeUpdate, a, b)
+			}
+			return
+		}
+		if a.Kind() == reflect.Pointer {
+			if a.Pointer() == b.Pointer() || d.seen(a, b) {
+				return
+			}
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		walkStructFields(typ, nil, "", func(sf structField) {
+			if sf.field.IsExported() {
+				d.diff(joinPath(path, sf.field.Name), a.FieldByIndex(sf.index), b.FieldByIndex(sf.index))
+			}
+		})
+	case reflect.Slice:
+		if d.nilChanged(a, b) {
+			d.add(path, ChangeUpdate, a, b)
+			return
+		}
+		n := a.Len()
+		if b.Len() < n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			d.diff(path+"["+strconv.Itoa(i)+"]", a.Index(i), b.Index(i))
+		}
+		for i := n; i < b.Len(); i++ {
+			d.add(path+"["+strconv.Itoa(i)+"]", ChangeCreate, reflect.Value{}, b.Index(i))
+		}
+		for i := n; i < a.Len(); i++ {
+			d.add(path+"["+strconv.Itoa(i)+"]", ChangeDelete, a.Index(i), reflect.Value{})
+		}
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			d.diff(path+"["+strconv.Itoa(i)+"]", a.Index(i), b.Index(i))
+		}
+	case reflect.Map:
+		if d.nilChanged(a, b) {
+			d.add(path, ChangeUpdate, a, b)
+			return
+		}
+		if a.Pointer() == b.Pointer() || d.seen(a, b) {
+			return
+		}
+		for _, k := range sortedKeys(a, b) {
+			av, bv := a.MapIndex(k.val), b.MapIndex(k.val)
+			switch {
+			case !bv.IsValid():
+				d.add(keyPath(path, k.str), ChangeDelete, av, bv)
+			case !av.IsValid():
+				d.add(keyPath(path, k.str), ChangeCreate, av, bv)
+			default:
+				d.diff(keyPath(path, k.str), av, bv)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.add(path, ChangeUpdate, a, b)
+		}
+	}
+}
+
+// seen 标记 a、b 已在比较中, 再次遇到时视为相等
+func (d *differ) seen(a, b reflect.Value) bool {
+	key := visit{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+	if d.visited[key] {
+		return true
+	}
+	d.visited[key] = true
+	return false
+}
+
+// nilChanged 一个为 nil 另一个不为 nil; WithNilEqualsEmpty 时 nil 与空值视为相等
+func (d *differ) nilChanged(a, b reflect.Value) bool {
+	if a.IsNil() == b.IsNil() {
+		return false
+	}
+	return !d.opts.nilEqualsEmpty || a.Len() != 0 || b.Len() != 0
+}
+
+// leafEqual time.Time 按 Equal 比较, []byte 按内容比较
+func (d *differ) leafEqual(a, b reflect.Value) bool {
+	switch {
+	case a.Type() == timeType:
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	case a.Kind() == reflect.Slice:
+		return bytes.Equal(a.Bytes(), b.Bytes()) && !d.nilChanged(a, b)
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+type sortedKey struct {
+	str string
+	val reflect.Value
+}
+
+// sortedKeys a 和 b 的所有 key, 按 key 本身去重, 按格式化后的字符串排序, 字符串相同时按类型排序
+func sortedKeys(a, b reflect.Value) []sortedKey {
+	seen := map[interface{}]bool{}
+	keys := make([]sortedKey, 0, a.Len()+b.Len())
+	for _, m := range []reflect.Value{a, b} {
+		for _, k := range m.MapKeys() {
+			if seen[k.Interface()] {
+				continue
+			}
+			seen[k.Interface()] = true
+			s, err := FormatInstanceToStr(k)
+			if err != nil {
+				s = k.String()
+			}
+			keys = append(keys, sortedKey{str: s, val: k})
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].str != keys[j].str {
+			return keys[i].str < keys[j].str
+		}
+		return typeName(keys[i].val) < typeName(keys[j].val)
+	})
+	return keys
+}
+
+func typeName(k reflect.Value) string {
+	if k.Kind() == reflect.Interface && !k.IsNil() {
+		return k.Elem().Type().String()
+	}
+	return k.Type().String()
+}
+
+// Apply 把 Diff 的结果应用到 dstPtr 指向的值上, 路径上的 nil 指针/map 会被分配;
+// ChangeDelete 删除 map key 或把 slice 截断到该下标
+func Apply(dstPtr interface{}, changes []Change) error {
+	v := reflect.ValueOf(dstPtr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.Errorf("apply: dstPtr must be a non-nil pointer, got %T", dstPtr)
+	}
+	o := newPathOptions(nil)
+	for _, c := range changes {
+		if err := o.apply(v.Elem(), c); err != nil {
+			return errors.Wrapf(err, "apply %s %s", c.Type, c.Path)
+		}
+	}
+	return nil
+}
+
+func (o *PathOptions) apply(v reflect.Value, c Change) error {
+	var segs []pathSeg
+	if c.Path != "" {
+		var err error
+		if segs, err = parsePath(c.Path); err != nil {
+			return err
+		}
+	}
+
+	if c.Type != ChangeDelete {
+		return o.setPath(v, segs, "", func(v reflect.Value) error {
+			if c.New == nil {
+				v.Set(reflect.Zero(v.Type()))
+				return nil
+			}
+			nv := reflect.ValueOf(c.New)
+			if !nv.Type().AssignableTo(v.Type()) {
+				if !nv.Type().ConvertibleTo(v.Type()) {
+					return errors.Errorf("%s is not assignable to %s", nv.Type(), v.Type())
+				}
+				nv = nv.Convert(v.Type())
+			}
+			v.Set(nv)
+			return nil
+		})
+	}
+
+	if len(segs) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	last := segs[len(segs)-1]
+	return o.setPath(v, segs[:len(segs)-1], "", func(v reflect.Value) error {
+		for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			key, err := o.parse.mapKey(v.Type(), last)
+			if err != nil {
+				return err
+			}
+			if !v.IsNil() {
+				v.SetMapIndex(key, reflect.Value{})
+			}
+			return nil
+		case reflect.Slice:
+			if !last.isIndex {
+				return errors.Errorf("%s: %s is not a map", last, v.Type())
+			}
+			if !v.CanSet() {
+				return errors.Errorf("%s: can not truncate %s", last, v.Type())
+			}
+			if last.index < v.Len() {
+				v.Set(v.Slice(0, last.index))
+			}
+			return nil
+		default:
+			return errors.Errorf("%s: can not delete from %s", last, v.Type())
+		}
+	})
+}
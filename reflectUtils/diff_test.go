@@ -0,0 +1,168 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type diffNode struct {
+	Val  int
+	Next *diffNode
+}
+
+func TestDeepCopy(t *testing.T) {
+	Convey("DeepCopy", t, func() {
+		o := newFlatOrder()
+		o.Extra = map[string]*string{"k": new(string)}
+		c := DeepCopy(o)
+		So(c, ShouldResemble, o)
+
+		c.Billing.City = "x"
+		c.Tags[0] = "x"
+		c.Items[0].City = "z"
+		c.Scores["math"] = 0
+		*c.Extra["k"] = "x"
+		So(o.Billing.City, ShouldEqual, "Shanghai")
+		So(o.Tags[0], ShouldEqual, "a")
+		So(o.Items[0].City, ShouldEqual, "x")
+		So(o.Scores["math"], ShouldEqual, 90)
+		So(*o.Extra["k"], ShouldEqual, "")
+
+		Convey("unexported fields are copied shallowly", func() {
+			So(c.secret, ShouldEqual, "s")
+		})
+
+		Convey("bytes and nil", func() {
+			b := []byte("abc")
+			cb := DeepCopy(b)
+			cb[0] = 'x'
+			So(string(b), ShouldEqual, "abc")
+
+			var p *diffNode
+			So(DeepCopy(p), ShouldBeNil)
+			So(DeepCopy[interface{}](nil), ShouldBeNil)
+		})
+
+		Convey("cycle", func() {
+			n := &diffNode{Val: 1}
+			n.Next = &diffNode{Val: 2, Next: n}
+			cn := DeepCopy(n)
+			So(cn, ShouldNotEqual, n)
+			So(cn.Next.Next, ShouldEqual, cn)
+			So(cn.Next.Val, ShouldEqual, 2)
+
+			m := map[string]interface{}{}
+			m["self"] = m
+			cm := DeepCopy(m)
+			So(reflect.ValueOf(cm["self"]).Pointer(), ShouldEqual, reflect.ValueOf(cm).Pointer())
+			So(reflect.ValueOf(cm).Pointer(), ShouldNotEqual, reflect.ValueOf(m).Pointer())
+		})
+	})
+}
+
+func TestDiff(t *testing.T) {
+	Convey("Diff", t, func() {
+		a := newFlatOrder()
+		b := DeepCopy(a)
+		So(Diff(a, b), ShouldBeEmpty)
+
+		b.Id = 8
+		b.Billing.Zip = ""
+		b.Shipping = &flatAddress{City: "x"}
+		b.Tags = append(b.Tags, "c")
+		b.Items = b.Items[:1]
+		b.Scores["art"] = 60
+		delete(b.Scores, "math")
+		b.Grid[1] = 5
+		b.secret = "changed"
+
+		changes := Diff(a, &b)
+		So(changes, ShouldResemble, []Change{{Path: "", Type: ChangeUpdate, Old: a, New: &b}})
+
+		changes = Diff(a, b)
+		So(changes, ShouldResemble, []Change{
+			{Path: "Id", Type: ChangeUpdate, Old: 7, New: 8},
+			{Path: "Billing.Zip", Type: ChangeUpdate, Old: "200000", New: ""},
+			{Path: "Shipping", Type: ChangeUpdate, Old: (*flatAddress)(nil), New: b.Shipping},
+			{Path: "Tags[2]", Type: ChangeCreate, Old: nil, New: "c"},
+			{Path: "Items[1]", Type: ChangeDelete, Old: a.Items[1], New: nil},
+			{Path: "Scores.art", Type: ChangeCreate, Old: nil, New: 60},
+			{Path: "Scores.math", Type: ChangeDelete, Old: 90, New: nil},
+			{Path: "Grid[1]", Type: ChangeUpdate, Old: 4, New: 5},
+		})
+
+		Convey("Apply", func() {
+			got := DeepCopy(a)
+			So(Apply(&got, changes), ShouldBeNil)
+			got.secret = b.secret
+			So(got, ShouldResemble, b)
+
+			So(Apply(got, changes), ShouldNotBeNil)
+			So(Apply(&got, []Change{{Path: "Id", New: "x"}}), ShouldNotBeNil)
+		})
+
+		Convey("time", func() {
+			t1 := time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC)
+			a := bindBase{Created: t1}
+			b := bindBase{Created: t1.In(time.FixedZone("CST", 8*3600))}
+			So(Diff(a, b), ShouldBeEmpty)
+
+			b.Created = t1.Add(time.Second)
+			So(Diff(a, b), ShouldHaveLength, 1)
+		})
+
+		Convey("nil and empty", func() {
+			a := flatOrder{Tags: nil, Scores: map[string]int{}}
+			b := flatOrder{Tags: []string{}, Scores: nil}
+			So(Diff(a, b), ShouldHaveLength, 2)
+			So(Diff(a, b, WithNilEqualsEmpty()), ShouldBeEmpty)
+		})
+
+		Convey("cycle", func() {
+			a := &diffNode{Val: 1}
+			a.Next = &diffNode{Val: 2, Next: a}
+			b := DeepCopy(a)
+			So(Diff(a, b), ShouldBeEmpty)
+
+			b.Next.Val = 3
+			So(Diff(a, b), ShouldResemble, []Change{{Path: "Next.Val", Type: ChangeUpdate, Old: 2, New: 3}})
+		})
+
+		Convey("interface", func() {
+			a := map[string]interface{}{"n": 1, "s": []interface{}{"x"}}
+			b := map[string]interface{}{"n": "1", "s": []interface{}{"y"}}
+			changes := Diff(a, b)
+			So(changes, ShouldResemble, []Change{
+				{Path: "n", Type: ChangeUpdate, Old: 1, New: "1"},
+				{Path: "s[0]", Type: ChangeUpdate, Old: "x", New: "y"},
+			})
+			So(Apply(&a, changes), ShouldBeNil)
+			So(a, ShouldResemble, b)
+		})
+
+		Convey("map key with separators", func() {
+			type labels struct {
+				M map[string]int
+			}
+			a := labels{M: map[string]int{"a.b": 1, "x[0]": 2, "": 3, `q"`: 4}}
+			b := labels{M: map[string]int{"a.b": 5, "x[0]": 6, "": 7, `q"`: 8}}
+			changes := Diff(a, b)
+			So(changes, ShouldResemble, []Change{
+				{Path: `M[""]`, Type: ChangeUpdate, Old: 3, New: 7},
+				{Path: `M["a.b"]`, Type: ChangeUpdate, Old: 1, New: 5},
+				{Path: `M["q\""]`, Type: ChangeUpdate, Old: 4, New: 8},
+				{Path: `M["x[0]"]`, Type: ChangeUpdate, Old: 2, New: 6},
+			})
+			So(Apply(&a, changes), ShouldBeNil)
+			So(a, ShouldResemble, b)
+		})
+
+		Convey("keys equal as strings", func() {
+			a := map[interface{}]int{1: 1, "1": 2}
+			b := map[interface{}]int{1: 1, "1": 3}
+			So(Diff(a, b), ShouldResemble, []Change{{Path: "1", Type: ChangeUpdate, Old: 2, New: 3}})
+		})
+	})
+}
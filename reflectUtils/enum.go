@@ -0,0 +1,71 @@
+package reflectUtils
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrEnumValue 字符串既不是注册的名字也不是数字
+var ErrEnumValue = errors.New("unknown enum value")
+
+// Integer 可作为枚举的整数类型
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// EnumConverter 由枚举值生成按 String() 名字解析的 ConvertFunc, 用于 WithConverter/Converters.Register.
+// 名字先精确匹配, 再忽略大小写匹配; 都不匹配时按数字解析.
+// 忽略大小写后相同的名字无法区分, 注册时 panic. 例如:
+//
+//	type Status uint8
+//	func (s Status) String() string { ... }
+//
+//	reflectUtils.RegisterEnum(StatusActive, StatusDisabled)
+func EnumConverter[T interface {
+	Integer
+	fmt.Stringer
+}](values ...T) (reflect.Type, ConvertFunc) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	names := make(map[string]T, len(values))
+	// folded 小写的名字 -> 值
+	folded := make(map[string]T, len(values))
+	for _, v := range values {
+		name := v.String()
+		if prev, exist := folded[strings.ToLower(name)]; exist && prev != v {
+			panic(errors.Errorf("EnumConverter(%s): %s and %s can not be told apart", typ, prev, name))
+		}
+		names[name] = v
+		folded[strings.ToLower(name)] = v
+	}
+
+	return typ, func(strVal string) (interface{}, error) {
+		if v, exist := names[strVal]; exist {
+			return v, nil
+		}
+		if v, exist := folded[strings.ToLower(strVal)]; exist {
+			return v, nil
+		}
+
+		n, err := newParseOptions(nil).getNumber(typ.Kind(), strVal)
+		if err != nil {
+			known := make([]string, 0, len(names))
+			for name := range names {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, errors.Wrapf(ErrEnumValue, "%s val=%s, expect one of %s", typ, strVal, strings.Join(known, ","))
+		}
+		return n.Convert(typ).Interface(), nil
+	}
+}
+
+// RegisterEnum 在全局注册表中注册枚举的名字表, 见 EnumConverter
+func RegisterEnum[T interface {
+	Integer
+	fmt.Stringer
+}](values ...T) {
+	RegisterConverter(EnumConverter(values...))
+}
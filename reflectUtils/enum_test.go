@@ -0,0 +1,89 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"testing"
+)
+
+type weekday uint8
+
+const (
+	monday weekday = iota + 1
+	tuesday
+)
+
+func (d weekday) String() string {
+	switch d {
+	case monday:
+		return "Monday"
+	case tuesday:
+		return "Tuesday"
+	}
+	return "unknown"
+}
+
+type priority int
+
+// flag 的名字只有大小写不同
+type flag uint8
+
+func (f flag) String() string {
+	return map[flag]string{1: "on", 2: "ON", 3: "off"}[f]
+}
+
+func (p priority) String() string {
+	return map[priority]string{-1: "low", 0: "normal", 1: "high"}[p]
+}
+
+func TestEnum(t *testing.T) {
+	Convey("per call", t, func() {
+		opt := WithConverter(EnumConverter(monday, tuesday))
+
+		for in, want := range map[string]weekday{
+			"Monday":  monday,
+			"tuesday": tuesday,
+			"2":       tuesday,
+			"":        0,
+		} {
+			got, err := ParseStrToInstance(reflect.ValueOf(weekday(0)), in, opt)
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, want)
+		}
+
+		_, err := ParseStrToInstance(reflect.ValueOf(weekday(0)), "Sunday", opt)
+		So(errors.Cause(err), ShouldEqual, ErrEnumValue)
+		So(err.Error(), ShouldContainSubstring, "Monday,Tuesday")
+
+		var p *weekday
+		got, err := ParseStrToInstance(reflect.ValueOf(p), "Monday", opt)
+		So(err, ShouldBeNil)
+		So(*got.Interface().(*weekday), ShouldEqual, monday)
+
+		// 未注册时按数字解析
+		_, err = ParseStrToInstance(reflect.ValueOf(weekday(0)), "Monday")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("global", t, func() {
+		RegisterEnum(priority(-1), priority(0), priority(1))
+
+		got, err := ParseStrToInstance(reflect.ValueOf(priority(0)), "low")
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, priority(-1))
+
+		got, err = ParseStrToInstance(reflect.ValueOf(priority(0)), "HIGH")
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, priority(1))
+
+		got, err = ParseStrToInstance(reflect.ValueOf(priority(0)), "-1")
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, priority(-1))
+	})
+
+	Convey("case collision", t, func() {
+		So(func() { EnumConverter(flag(1), flag(2)) }, ShouldPanic)
+		So(func() { EnumConverter(flag(1), flag(3), flag(1)) }, ShouldNotPanic)
+	})
+}
@@ -0,0 +1,162 @@
+package reflectUtils
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldInfo 结构体中一个导出字段的元数据, 由 CachedFields 缓存并共享, 不要修改
+type FieldInfo struct {
+	Field reflect.StructField
+	// Index 相对于最外层结构体的 FieldByIndex 路径
+	Index []int
+	// Path 字段名路径, 如 Base.Id
+	Path string
+	// Name tag 中逗号前的名字, 没有 tag 时为字段名
+	Name string
+	// Tagged 是否有名字非空的 tag
+	Tagged bool
+	// Skip tag 为 -
+	Skip bool
+	// Options tag 中逗号后的选项, 如 omitempty
+	Options []string
+
+	// converter 字段类型在全局注册表中的解析函数, 可为空
+	converter ConvertFunc
+	// seps sep/kvsep tag
+	seps fieldSeps
+}
+
+// HasOption tag 的选项中是否包含 opt
+func (f *FieldInfo) HasOption(opt string) bool {
+	for _, o := range f.Options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// StructInfo 结构体按某个 tag 解析后的字段元数据
+type StructInfo struct {
+	Type reflect.Type
+	// Fields 所有导出字段(含打平的匿名结构体字段), 按定义顺序
+	Fields []*FieldInfo
+
+	byName map[string]*FieldInfo
+	byPath map[string]*FieldInfo
+	// flat 所有字段(含未导出字段), FlatStructFields 的结果
+	flat []reflect.StructField
+}
+
+// ByName 按 Name 查找未被 Skip 的字段, 同名时取第一个
+func (s *StructInfo) ByName(name string) (*FieldInfo, bool) {
+	f, ok := s.byName[name]
+	return f, ok
+}
+
+// ByPath 按 Path(如 Base.Id)查找字段, 包括被 Skip 的字段
+func (s *StructInfo) ByPath(path string) (*FieldInfo, bool) {
+	f, ok := s.byPath[path]
+	return f, ok
+}
+
+type fieldCacheKey struct {
+	typ reflect.Type
+	tag string
+	// gen 生成时的 fieldCacheGen, RegisterConverter 之后旧的缓存不再被命中
+	gen uint64
+}
+
+var (
+	// fieldCache fieldCacheKey -> *StructInfo
+	fieldCache sync.Map
+	// fieldCacheGen 全局注册表的版本, 只能原子读写
+	fieldCacheGen uint64
+)
+
+// CachedFields typ(结构体或其指针)按 tag 解析的字段元数据, 结果按 reflect.Type 和 tag 缓存, 并发安全.
+// RegisterConverter 会清空缓存
+func CachedFields(typ reflect.Type, tag string) *StructInfo {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	key := fieldCacheKey{typ: typ, tag: tag, gen: atomic.LoadUint64(&fieldCacheGen)}
+	if s, ok := fieldCache.Load(key); ok {
+		return s.(*StructInfo)
+	}
+	s, _ := fieldCache.LoadOrStore(key, newStructInfo(typ, tag))
+	return s.(*StructInfo)
+}
+
+func newStructInfo(typ reflect.Type, tag string) *StructInfo {
+	s := &StructInfo{Type: typ, byName: map[string]*FieldInfo{}, byPath: map[string]*FieldInfo{}}
+	if typ.Kind() != reflect.Struct {
+		return s
+	}
+	walkStructFields(typ, nil, "", func(sf structField) {
+		s.flat = append(s.flat, sf.field)
+		if !sf.field.IsExported() {
+			return
+		}
+		f := &FieldInfo{Field: sf.field, Index: sf.index, Path: sf.path, Name: sf.field.Name}
+		f.converter, _ = defaultConverters.Lookup(sf.field.Type)
+		f.seps = fieldSepsOf(sf.field)
+		if v, ok := sf.field.Tag.Lookup(tag); ok && tag != "" {
+			parts := strings.Split(v, ",")
+			switch parts[0] {
+			case "-":
+				f.Skip = true
+			case "":
+			default:
+				f.Name, f.Tagged = parts[0], true
+			}
+			f.Options = parts[1:]
+		}
+		s.Fields = append(s.Fields, f)
+		s.byPath[f.Path] = f
+		if _, dup := s.byName[f.Name]; !dup && !f.Skip {
+			s.byName[f.Name] = f
+		}
+	})
+	return s
+}
+
+// resetFieldCache 全局注册表变化后, 缓存中的 converter 失效.
+// 先递增版本再删除旧版本的缓存: 并发的 CachedFields 即使在删除后写入了旧版本的结果,
+// 也不会再被命中
+func resetFieldCache() {
+	gen := atomic.AddUint64(&fieldCacheGen, 1)
+	fieldCache.Range(func(key, _ interface{}) bool {
+		if key.(fieldCacheKey).gen != gen {
+			fieldCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// NewParseOptions 生成可复用的选项, 批量解析时避免每次调用都重新生成
+func NewParseOptions(opts ...ParseOpt) *ParseOptions {
+	return newParseOptions(opts)
+}
+
+// Parse 同 ParseStrToInstance
+func (o *ParseOptions) Parse(zeroVal reflect.Value, strVal string) (reflect.Value, error) {
+	return o.parse(zeroVal, strVal)
+}
+
+// ParseField 把 strVal 解析为字段 f 的类型, 使用缓存的全局解析函数, 不再查询全局注册表;
+// 字段的 sep/kvsep tag 优先于选项
+func (o *ParseOptions) ParseField(f *FieldInfo, strVal string) (reflect.Value, error) {
+	typ := f.Field.Type
+	o = o.withSeps(f.seps, typ)
+	if fn, exist := o.converters.Lookup(typ); exist {
+		return convertWith(fn, typ, strVal)
+	}
+	if f.converter != nil {
+		return convertWith(f.converter, typ, strVal)
+	}
+	return o.parseBuiltin(reflect.Zero(typ), strVal)
+}
@@ -0,0 +1,145 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type cacheTagged struct {
+	bindBase
+	Name   string `json:"name,omitempty"`
+	Hidden string `json:"-"`
+	Raw    string `json:",string"`
+	Code   cacheCode
+	secret string
+}
+
+type cacheCode string
+
+func TestCachedFields(t *testing.T) {
+	Convey("CachedFields", t, func() {
+		typ := reflect.TypeOf(cacheTagged{})
+		s := CachedFields(typ, "json")
+		So(CachedFields(reflect.PtrTo(typ), "json"), ShouldEqual, s)
+		So(CachedFields(typ, ""), ShouldNotEqual, s)
+
+		names := make([]string, 0, len(s.Fields))
+		for _, f := range s.Fields {
+			names = append(names, f.Path+"="+f.Name)
+		}
+		So(names, ShouldResemble, []string{
+			"bindBase.Id=id", "bindBase.Created=created", "Name=name", "Hidden=Hidden", "Raw=Raw", "Code=Code",
+		})
+
+		f, ok := s.ByName("name")
+		So(ok, ShouldBeTrue)
+		So(f.Tagged, ShouldBeTrue)
+		So(f.HasOption("omitempty"), ShouldBeTrue)
+		So(f.Index, ShouldResemble, []int{1})
+
+		f, ok = s.ByName("id")
+		So(ok, ShouldBeTrue)
+		So(f.Index, ShouldResemble, []int{0, 0})
+
+		_, ok = s.ByName("Hidden")
+		So(ok, ShouldBeFalse)
+		f, _ = s.ByName("Raw")
+		So(f.Tagged, ShouldBeFalse)
+		So(f.HasOption("string"), ShouldBeTrue)
+	})
+
+	Convey("ParseField", t, func() {
+		s := CachedFields(reflect.TypeOf(cacheTagged{}), "json")
+		code, _ := s.ByName("Code")
+		o := NewParseOptions()
+
+		v, err := o.ParseField(code, "abc")
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldEqual, cacheCode("abc"))
+
+		// RegisterConverter 使缓存失效
+		RegisterConverter(reflect.TypeOf(cacheCode("")), func(s string) (interface{}, error) {
+			return strings.ToUpper(s), nil
+		})
+		defer func() {
+			defaultConverters.mu.Lock()
+			delete(defaultConverters.m, reflect.TypeOf(cacheCode("")))
+			defaultConverters.mu.Unlock()
+			resetFieldCache()
+		}()
+		code, _ = CachedFields(reflect.TypeOf(cacheTagged{}), "json").ByName("Code")
+		v, err = o.ParseField(code, "abc")
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldEqual, cacheCode("ABC"))
+
+		// 单次调用的注册表优先
+		v, err = NewParseOptions(WithConverter(reflect.TypeOf(cacheCode("")), func(s string) (interface{}, error) {
+			return "local", nil
+		})).ParseField(code, "abc")
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldEqual, cacheCode("local"))
+
+		id, _ := CachedFields(reflect.TypeOf(cacheTagged{}), "json").ByName("id")
+		v, err = NewParseOptions(WithThousandSeparator(',')).ParseField(id, "1,000")
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldEqual, 1000)
+	})
+
+	Convey("concurrent", t, func() {
+		type concurrent struct{ A, B int }
+		var wg sync.WaitGroup
+		res := make([]*StructInfo, 16)
+		for i := range res {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				res[i] = CachedFields(reflect.TypeOf(concurrent{}), "json")
+			}(i)
+		}
+		wg.Wait()
+		for _, s := range res {
+			So(s, ShouldEqual, res[0])
+		}
+	})
+
+	Convey("reset while filling", t, func() {
+		type stale struct{ A int }
+		typ := reflect.TypeOf(stale{})
+		old := CachedFields(typ, "json")
+
+		// 模拟与 resetFieldCache 并发的 CachedFields: 用旧版本生成的结果在重置之后才写入
+		gen := atomic.LoadUint64(&fieldCacheGen)
+		resetFieldCache()
+		fieldCache.Store(fieldCacheKey{typ: typ, tag: "json", gen: gen}, old)
+
+		So(CachedFields(typ, "json"), ShouldNotEqual, old)
+		So(CachedFields(typ, "json"), ShouldEqual, CachedFields(typ, "json"))
+	})
+}
+
+func BenchmarkParseField(b *testing.B) {
+	typ := reflect.TypeOf(cacheTagged{})
+	b.Run("ParseStrToInstance", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v := reflect.New(typ).Elem()
+			field := v.FieldByName("Name")
+			nv, _ := ParseStrToInstance(field, "tom")
+			field.Set(nv)
+		}
+	})
+	b.Run("ParseField", func(b *testing.B) {
+		b.ReportAllocs()
+		f, _ := CachedFields(typ, "json").ByName("name")
+		o := NewParseOptions()
+		for i := 0; i < b.N; i++ {
+			v := reflect.New(typ).Elem()
+			nv, _ := o.ParseField(f, "tom")
+			v.FieldByIndex(f.Index).Set(nv)
+		}
+	})
+}
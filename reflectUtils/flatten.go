@@ -0,0 +1,269 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ToMap 把结构体(或其指针)转为 map[string]interface{}:
+// key 为 tag 的名字, 没有 tag 时为字段名; tag 为 - 的字段和未导出字段被跳过,
+// 带 omitempty 的字段为空值时被跳过; 匿名结构体字段被打平.
+// 嵌套的结构体/map 转为 map[string]interface{}, slice/array 转为 []interface{},
+// time.Time 等实现了 Marshaler 的类型和基本类型保持原值
+func ToMap(v interface{}, tag string) (map[string]interface{}, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, errors.Errorf("ToMap: v must be a struct or struct pointer, got %T", v)
+	}
+	m, err := toMapValue(val, tag)
+	if err != nil {
+		return nil, err
+	}
+	return m.(map[string]interface{}), nil
+}
+
+func toMapValue(v reflect.Value, tag string) (interface{}, error) {
+	if isLeafType(v.Type()) {
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return toMapValue(v.Elem(), tag)
+	case reflect.Struct:
+		res := make(map[string]interface{}, v.NumField())
+		err := eachField(v, tag, func(key string, field reflect.Value) error {
+			fv, err := toMapValue(field, tag)
+			if err != nil {
+				return errors.Wrap(err, key)
+			}
+			res[key] = fv
+			return nil
+		})
+		return res, err
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []interface{}(nil), nil
+		}
+		res := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ev, err := toMapValue(v.Index(i), tag)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[%d]", i)
+			}
+			res[i] = ev
+		}
+		return res, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return map[string]interface{}(nil), nil
+		}
+		res := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := FormatInstanceToStr(iter.Key())
+			if err != nil {
+				return nil, errors.Wrap(err, "map key")
+			}
+			ev, err := toMapValue(iter.Value(), tag)
+			if err != nil {
+				return nil, errors.Wrap(err, key)
+			}
+			res[key] = ev
+		}
+		return res, nil
+	default:
+		return nil, errors.Errorf("ToMap: not support %s", v.Kind().String())
+	}
+}
+
+// Flatten 把结构体(或其指针)转为一层的 map[string]string, key 为路径,
+// 如 address.city、tags[0]、scores.math; 字段名规则与 ToMap 相同, 叶子的值为 FormatInstanceToStr 的结果.
+// nil 指针/interface 输出为 WithNilString 指定的字符串, 空的 slice/map 不输出任何 key.
+// map 的 key 中不能包含 . [ ], 否则 Unflatten 无法还原
+func Flatten(v interface{}, tag string, opts ...FormatOpt) (map[string]string, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, errors.Errorf("Flatten: v must be a struct or struct pointer, got %T", v)
+	}
+	res := make(map[string]string)
+	if err := newFormatOptions(opts).flatten(res, "", val, tag); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (o *FormatOptions) flatten(res map[string]string, prefix string, v reflect.Value, tag string) error {
+	// 注册了实现的接口整体输出, 以保留判别字段
+	if isLeafType(v.Type()) || v.Kind() == reflect.Interface && HasVariants(v.Type()) {
+		s, err := o.format(v)
+		if err != nil {
+			return errors.Wrap(err, prefix)
+		}
+		res[prefix] = s
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			res[prefix] = o.nilStr
+			return nil
+		}
+		return o.flatten(res, prefix, v.Elem(), tag)
+	case reflect.Struct:
+		return eachField(v, tag, func(key string, field reflect.Value) error {
+			return o.flatten(res, joinPath(prefix, key), field, tag)
+		})
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := o.flatten(res, prefix+"["+strconv.Itoa(i)+"]", v.Index(i), tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := o.format(iter.Key())
+			if err != nil {
+				return errors.Wrapf(err, "%s map key", prefix)
+			}
+			if err := o.flatten(res, joinPath(prefix, key), iter.Value(), tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("Flatten %s: not support %s", prefix, v.Kind().String())
+	}
+}
+
+// Unflatten 是 Flatten 的逆操作, 把路径形式的 m 绑定到 dst(结构体指针),
+// 名字先按 tag 匹配, 再按字段名匹配; nil 指针/map 会被分配, slice 按下标扩展.
+// 所有 key 的错误以 BindErrors 一起返回. opts 中 WithPathTag 不生效, 由 tag 指定
+func Unflatten(dst interface{}, m map[string]string, tag string, opts ...PathOpt) error {
+	dstVal := reflect.ValueOf(dst)
+	if dstVal.Kind() != reflect.Pointer || dstVal.IsNil() || dstVal.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("Unflatten: dst must be a non-nil struct pointer, got %T", dst)
+	}
+	o := newPathOptions(opts)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs BindErrors
+	for _, k := range keys {
+		segs, err := parsePath(k)
+		if err == nil {
+			err = o.setPath(dstVal.Elem(), segs, tag, o.parse.parseTo(m[k]))
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Field: k, Key: k, Value: m[k], Err: err})
+		}
+	}
+	if o.parse.defaults {
+		if err := o.parse.ApplyDefaults(dst); err != nil {
+			errs = append(errs, err.(BindErrors)...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// eachField 按顺序遍历导出字段, 跳过 tag 为 - 的字段和 omitempty 的空值
+func eachField(v reflect.Value, tag string, fn func(key string, field reflect.Value) error) error {
+	var err error
+	keyOf := tagOrFieldName(tag)
+	walkStructFields(v.Type(), nil, "", func(sf structField) {
+		if err != nil || !sf.field.IsExported() {
+			return
+		}
+		key, ok := keyOf(sf.field)
+		if !ok {
+			return
+		}
+		field := v.FieldByIndex(sf.index)
+		if hasTagOption(sf.field, tag, "omitempty") && isEmptyValue(field) {
+			return
+		}
+		err = fn(key, field)
+	})
+	return err
+}
+
+// hasTagOption tag 中逗号后的选项是否包含 opt
+func hasTagOption(ft reflect.StructField, tag, opt string) bool {
+	if tag == "" {
+		return false
+	}
+	parts := strings.Split(ft.Tag.Get(tag), ",")
+	for _, p := range parts[1:] {
+		if p == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue 与 encoding/json 的 omitempty 一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
+
+// isLeafType 不再展开的类型: 基本类型、time.Time、time.Duration、[]byte、
+// 实现了 encoding.TextMarshaler/json.Marshaler 的类型
+func isLeafType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return false
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+	}
+	if typ == timeType || typ == durationType {
+		return true
+	}
+	ptr := reflect.PtrTo(typ)
+	if ptr.Implements(textMarshalerType) || ptr.Implements(jsonMarshalerType) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return false
+	}
+	return true
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,175 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+type flatAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type flatOrder struct {
+	bindBase
+	Customer string             `json:"customer"`
+	Address  flatAddress        `json:"address"`
+	Billing  *flatAddress       `json:"billing"`
+	Shipping *flatAddress       `json:"shipping,omitempty"`
+	Tags     []string           `json:"tags"`
+	Items    []flatAddress      `json:"items"`
+	Scores   map[string]int     `json:"scores"`
+	Weights  map[int]float64    `json:"weights,omitempty"`
+	Grid     [2]int             `json:"grid"`
+	Note     *string            `json:"note"`
+	Level    level              `json:"level"`
+	Extra    map[string]*string `json:"-"`
+	secret   string
+}
+
+func newFlatOrder() flatOrder {
+	return flatOrder{
+		bindBase: bindBase{Id: 7, Created: time.Date(2023, 8, 7, 0, 34, 0, 0, time.Local)},
+		Customer: "tom",
+		Address:  flatAddress{City: "Hangzhou"},
+		Billing:  &flatAddress{City: "Shanghai", Zip: "200000"},
+		Tags:     []string{"a", "b"},
+		Items:    []flatAddress{{City: "x"}, {City: "y", Zip: "1"}},
+		Scores:   map[string]int{"math": 90},
+		Grid:     [2]int{3, 4},
+		Level:    level(2),
+		secret:   "s",
+	}
+}
+
+func TestToMap(t *testing.T) {
+	Convey("ToMap", t, func() {
+		o := newFlatOrder()
+		m, err := ToMap(&o, "json")
+		So(err, ShouldBeNil)
+		So(m, ShouldResemble, map[string]interface{}{
+			"id":       7,
+			"created":  o.Created,
+			"customer": "tom",
+			"address":  map[string]interface{}{"city": "Hangzhou"},
+			"billing":  map[string]interface{}{"city": "Shanghai", "zip": "200000"},
+			"tags":     []interface{}{"a", "b"},
+			"items": []interface{}{
+				map[string]interface{}{"city": "x"},
+				map[string]interface{}{"city": "y", "zip": "1"},
+			},
+			"scores": map[string]interface{}{"math": 90},
+			"grid":   []interface{}{3, 4},
+			"note":   nil,
+			"level":  level(2),
+		})
+
+		Convey("without tag", func() {
+			m, err := ToMap(flatAddress{City: "x"}, "")
+			So(err, ShouldBeNil)
+			So(m, ShouldResemble, map[string]interface{}{"City": "x", "Zip": ""})
+		})
+
+		Convey("not a struct", func() {
+			_, err := ToMap(map[string]int{}, "json")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	Convey("Flatten", t, func() {
+		o := newFlatOrder()
+		flat, err := Flatten(o, "json")
+		So(err, ShouldBeNil)
+		So(flat, ShouldResemble, map[string]string{
+			"id":            "7",
+			"created":       "2023-08-07 00:34:00",
+			"customer":      "tom",
+			"address.city":  "Hangzhou",
+			"billing.city":  "Shanghai",
+			"billing.zip":   "200000",
+			"tags[0]":       "a",
+			"tags[1]":       "b",
+			"items[0].city": "x",
+			"items[1].city": "y",
+			"items[1].zip":  "1",
+			"scores.math":   "90",
+			"grid[0]":       "3",
+			"grid[1]":       "4",
+			"note":          "",
+			"level":         "high",
+		})
+
+		Convey("round trip", func() {
+			var got flatOrder
+			So(Unflatten(&got, flat, "json"), ShouldBeNil)
+			o.secret = ""
+			So(got, ShouldResemble, o)
+		})
+
+		Convey("nil string", func() {
+			flat, err := Flatten(flatAddress{}, "json", WithNilString("null"))
+			So(err, ShouldBeNil)
+			So(flat, ShouldResemble, map[string]string{"city": ""})
+
+			flat, err = Flatten(&flatOrder{}, "json", WithNilString("null"))
+			So(err, ShouldBeNil)
+			So(flat["billing"], ShouldEqual, "null")
+			var got flatOrder
+			So(Unflatten(&got, flat, "json"), ShouldBeNil)
+			So(got.Billing, ShouldBeNil)
+		})
+	})
+
+	Convey("Unflatten", t, func() {
+		Convey("field name and grow", func() {
+			var got flatOrder
+			err := Unflatten(&got, map[string]string{
+				"Customer":      "tom",
+				"tags[2]":       "c",
+				"items[1].City": "y",
+				"shipping.city": "x",
+				"weights.3":     "0.5",
+			}, "json")
+			So(err, ShouldBeNil)
+			So(got.Customer, ShouldEqual, "tom")
+			So(got.Tags, ShouldResemble, []string{"", "", "c"})
+			So(got.Items, ShouldResemble, []flatAddress{{}, {City: "y"}})
+			So(got.Shipping, ShouldResemble, &flatAddress{City: "x"})
+			So(got.Weights, ShouldResemble, map[int]float64{3: 0.5})
+		})
+
+		Convey("errors", func() {
+			var got flatOrder
+			err := Unflatten(&got, map[string]string{
+				"id":       "x",
+				"missing":  "1",
+				"grid[2]":  "1",
+				"tags.a":   "1",
+				"bad[":     "1",
+				"customer": "ok",
+			}, "json")
+			errs, ok := err.(BindErrors)
+			So(ok, ShouldBeTrue)
+			So(len(errs), ShouldEqual, 5)
+			So(errs[0].Field, ShouldEqual, "bad[")
+			So(got.Customer, ShouldEqual, "ok")
+
+			So(Unflatten(got, nil, "json"), ShouldNotBeNil)
+		})
+
+		Convey("max index", func() {
+			var got flatOrder
+			err := Unflatten(&got, map[string]string{"tags[2000000000]": "a"}, "json")
+			So(err, ShouldNotBeNil)
+			So(got.Tags, ShouldBeNil)
+
+			err = Unflatten(&got, map[string]string{"tags[3]": "a"}, "json", WithMaxPathIndex(2))
+			So(err, ShouldNotBeNil)
+			So(Unflatten(&got, map[string]string{"tags[2]": "a"}, "json", WithMaxPathIndex(2)), ShouldBeNil)
+			So(got.Tags, ShouldResemble, []string{"", "", "a"})
+		})
+	})
+}
@@ -0,0 +1,182 @@
+package reflectUtils
+
+import (
+	"encoding"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeLayout FormatInstanceToStr 默认的时间格式, 可由默认的 TimeParser 解析;
+// 小数秒只在不为 0 时输出, 如 2023-08-07 00:34:00、2023-08-07 00:34:00.5
+const DefaultTimeLayout = "2006-01-02 15:04:05.999999999"
+
+// FormatOptions FormatInstanceToStr 的选项
+type FormatOptions struct {
+	timeLayout   string
+	timeLocation *time.Location
+	nilStr       string
+}
+
+type FormatOpt func(o *FormatOptions)
+
+// WithTimeLayout time.Time 的格式, 默认 DefaultTimeLayout
+func WithTimeLayout(layout string) FormatOpt {
+	return func(o *FormatOptions) {
+		o.timeLayout = layout
+	}
+}
+
+// WithTimeLocation 格式化前把时间转换到 loc, 默认 time.Local(与解析一致)
+func WithTimeLocation(loc *time.Location) FormatOpt {
+	return func(o *FormatOptions) {
+		o.timeLocation = loc
+	}
+}
+
+// WithNilString nil 指针、nil map/slice/interface 的输出, 默认空字符串
+func WithNilString(s string) FormatOpt {
+	return func(o *FormatOptions) {
+		o.nilStr = s
+	}
+}
+
+func newFormatOptions(opts []FormatOpt) *FormatOptions {
+	o := &FormatOptions{
+		timeLayout:   DefaultTimeLayout,
+		timeLocation: time.Local,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// FormatInstanceToStr
+// 把值翻译为字符串, 是 ParseStrToInstance 的逆操作:
+// ParseStrToInstance(zero, FormatInstanceToStr(x)) == x
+// 例外: time.Time 的零值格式化为空字符串, 而空字符串解析为 1970-01-01(见 TimeParser.Parse);
+// 时间往返后时区为 WithTimeLocation 指定的时区, 与原值 Equal 但不一定 DeepEqual
+//
+// 规则:
+// 数字、bool: strconv, 浮点数取最短的精确表示
+// time.Time: WithTimeLayout 指定的格式, 零值为空字符串
+// time.Duration: Duration.String(), 如 1h30m0s
+// 实现了 encoding.TextMarshaler/json.Marshaler 的类型: MarshalText/MarshalJSON
+// struct/map/slice/array: json
+// RegisterVariant 注册了实现的接口类型: 具体值的 json, 加上判别字段, 嵌套在 struct/map/slice/array 中时同样处理
+// nil 指针/map/slice/interface: WithNilString 指定的字符串
+//
+// not supported:
+// reflect.Chan
+// reflect.Func
+// reflect.UnsafePointer
+func FormatInstanceToStr(v reflect.Value, opts ...FormatOpt) (string, error) {
+	return newFormatOptions(opts).format(v)
+}
+
+func (o *FormatOptions) format(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return o.nilStr, nil
+	}
+
+	typ := v.Type()
+	if typ == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return "", nil
+		}
+		return t.In(o.timeLocation).Format(o.timeLayout), nil
+	}
+	if typ == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return o.nilStr, nil
+		}
+	}
+
+	if v.Kind() == reflect.Interface {
+		if s, ok, err := o.formatVariant(v); ok {
+			return s, err
+		}
+	}
+	if s, ok, err := o.marshal(v); ok {
+		return s, err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Complex64:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, 64), nil
+	case reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'f', -1, 128), nil
+	case reflect.Pointer, reflect.Interface:
+		return o.format(v.Elem())
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		b, err := o.marshalJSON(v)
+		if err != nil {
+			return "", errors.Wrapf(err, "json.Marshal(%s)", typ)
+		}
+		return string(b), nil
+	default:
+		return "", errors.Errorf("FormatInstanceToStr(%s) not support %s", typ, v.Kind().String())
+	}
+}
+
+// marshal 使用 MarshalText(优先)或 MarshalJSON, 未实现时 ok=false.
+// 值方法和指针方法都会被使用, 后者需要复制一份可寻址的值
+func (o *FormatOptions) marshal(v reflect.Value) (s string, ok bool, err error) {
+	typ := v.Type()
+	if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Interface {
+		// 解引用后再判断, 与 ParseStrToInstance 一致
+		return "", false, nil
+	}
+
+	ptr := reflect.PtrTo(typ)
+	if !ptr.Implements(textMarshalerType) && !ptr.Implements(jsonMarshalerType) {
+		return "", false, nil
+	}
+	if !v.CanAddr() {
+		cp := reflect.New(typ)
+		cp.Elem().Set(v)
+		v = cp.Elem()
+	}
+
+	switch m := v.Addr().Interface().(type) {
+	case encoding.TextMarshaler:
+		b, err := m.MarshalText()
+		if err != nil {
+			return "", true, errors.Wrapf(err, "MarshalText(%s)", typ)
+		}
+		return string(b), true, nil
+	case json.Marshaler:
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return "", true, errors.Wrapf(err, "MarshalJSON(%s)", typ)
+		}
+		return string(b), true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,185 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+func (l level) MarshalText() ([]byte, error) {
+	switch l {
+	case 1:
+		return []byte("low"), nil
+	case 2:
+		return []byte("high"), nil
+	}
+	return nil, nil
+}
+
+type person struct {
+	Name   string         `json:"name"`
+	Age    int            `json:"age"`
+	Tags   []string       `json:"tags"`
+	Scores map[string]int `json:"scores"`
+}
+
+// roundTrip ParseStrToInstance(FormatInstanceToStr(x)) 是否等于 x
+func roundTrip[T any](x T) bool {
+	s, err := FormatInstanceToStr(reflect.ValueOf(x))
+	if err != nil {
+		return false
+	}
+	var zero T
+	got, err := ParseStrToInstance(reflect.ValueOf(zero), s)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got.Interface(), x)
+}
+
+func TestFormatInstanceToStr(t *testing.T) {
+	Convey("format", t, func() {
+		Convey("basic", func() {
+			for _, c := range []struct {
+				v    interface{}
+				want string
+			}{
+				{"tom", "tom"},
+				{-13, "-13"},
+				{uint8(255), "255"},
+				{true, "true"},
+				{17.23, "17.23"},
+				{float32(0.1), "0.1"},
+				{2.98109e+12, "2981090000000"},
+				{complex(1.5, -2), "(1.5-2i)"},
+				{level(2), "high"},
+				{[]int{1, 2}, "[1,2]"},
+				{map[string]int{"a": 1}, `{"a":1}`},
+				{person{Name: "tom", Age: 13}, `{"name":"tom","age":13,"tags":null,"scores":null}`},
+			} {
+				got, err := FormatInstanceToStr(reflect.ValueOf(c.v))
+				So(err, ShouldBeNil)
+				So(got, ShouldEqual, c.want)
+			}
+		})
+
+		Convey("alias", func() {
+			type status uint8
+			type name string
+			got, err := FormatInstanceToStr(reflect.ValueOf(status(3)))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "3")
+			got, err = FormatInstanceToStr(reflect.ValueOf(name("jack")))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "jack")
+		})
+
+		Convey("pointer", func() {
+			i := 98
+			got, err := FormatInstanceToStr(reflect.ValueOf(&i))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "98")
+
+			var p *int
+			got, err = FormatInstanceToStr(reflect.ValueOf(p))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "")
+
+			got, err = FormatInstanceToStr(reflect.ValueOf(p), WithNilString("null"))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "null")
+
+			var s []int
+			got, err = FormatInstanceToStr(reflect.ValueOf(s))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "")
+		})
+
+		Convey("time", func() {
+			tm := time.Date(2023, time.August, 7, 0, 34, 0, 0, time.Local)
+			got, err := FormatInstanceToStr(reflect.ValueOf(tm))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "2023-08-07 00:34:00")
+
+			got, err = FormatInstanceToStr(reflect.ValueOf(tm), WithTimeLayout("2006/01/02"))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "2023/08/07")
+
+			utc := time.Date(2023, time.August, 7, 0, 34, 0, 0, time.UTC)
+			got, err = FormatInstanceToStr(reflect.ValueOf(utc), WithTimeLocation(time.FixedZone("CST", 8*3600)), WithTimeLayout(time.RFC3339))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "2023-08-07T08:34:00+08:00")
+
+			got, err = FormatInstanceToStr(reflect.ValueOf(time.Time{}))
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, "")
+		})
+
+		Convey("not support", func() {
+			_, err := FormatInstanceToStr(reflect.ValueOf(make(chan int)))
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("round trip", t, func() {
+		So(quick.Check(roundTrip[string], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[bool], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[int], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[int8], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[int16], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[int32], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[int64], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[uint], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[uint8], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[uint16], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[uint32], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[uint64], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[float32], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[float64], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[[]string], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[map[string]int], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[[3]int], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[person], nil), ShouldBeNil)
+
+		type status uint8
+		type name string
+		So(quick.Check(roundTrip[status], nil), ShouldBeNil)
+		So(quick.Check(roundTrip[name], nil), ShouldBeNil)
+
+		So(quick.Check(func(i int) bool { return roundTrip(&i) }, nil), ShouldBeNil)
+		So(quick.Check(func(p person) bool { return roundTrip(&p) }, nil), ShouldBeNil)
+		So(roundTrip((*int)(nil)), ShouldBeTrue)
+
+		So(roundTrip(level(1)), ShouldBeTrue)
+		So(roundTrip(level(2)), ShouldBeTrue)
+		So(roundTrip(complex64(complex(1.5, -2))), ShouldBeTrue)
+		So(roundTrip(math.MaxFloat64), ShouldBeTrue)
+		So(roundTrip(math.SmallestNonzeroFloat64), ShouldBeTrue)
+
+		// 时间精确到纳秒, 按 Equal 比较
+		So(quick.Check(func(sec uint32, nsec uint32) bool {
+			tm := time.Unix(int64(sec), int64(nsec%1e9)).In(time.Local)
+			s, err := FormatInstanceToStr(reflect.ValueOf(tm))
+			if err != nil {
+				return false
+			}
+			got, err := ParseStrToInstance(reflect.ValueOf(time.Time{}), s)
+			return err == nil && got.Interface().(time.Time).Equal(tm)
+		}, nil), ShouldBeNil)
+
+		// 例外: 零值格式化为空字符串, 空字符串解析为 1970-01-01
+		s, err := FormatInstanceToStr(reflect.ValueOf(time.Time{}))
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "")
+		got, err := ParseStrToInstance(reflect.ValueOf(time.Time{}), s)
+		So(err, ShouldBeNil)
+		So(got.Interface().(time.Time).Equal(time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local)), ShouldBeTrue)
+
+		s, err = FormatInstanceToStr(reflect.ValueOf(time.Date(2023, 8, 7, 0, 34, 0, 500e6, time.Local)))
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "2023-08-07 00:34:00.5")
+	})
+}
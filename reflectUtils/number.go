@@ -0,0 +1,351 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNumberOverflow 数值超出目标类型的范围
+	ErrNumberOverflow = errors.New("number overflow")
+	// ErrNumberPrecision 数值无法被目标类型精确表示, 如 1.5 解析为 int
+	ErrNumberPrecision = errors.New("number precision loss")
+	// ErrNumberNotFinite NaN 或 Inf
+	ErrNumberNotFinite = errors.New("number not finite")
+)
+
+// WithStrict 严格模式:
+// 精度丢失(小数解析为整数, 整数超出浮点数的精确表示范围)以及 NaN/Inf 返回错误,
+// 非严格模式下小数截断为整数. 超出目标类型范围的数字在两种模式下都返回 ErrNumberOverflow
+func WithStrict() ParseOpt {
+	return func(o *ParseOptions) {
+		o.strict = true
+	}
+}
+
+// WithThousandSeparator 接受千分位分隔符, 如 1,234,567; 分隔符必须按三位分组.
+// sep 不能是小数点
+func WithThousandSeparator(sep rune) ParseOpt {
+	return func(o *ParseOptions) {
+		o.thousandSep = sep
+	}
+}
+
+// WithUnderscores 接受数字之间的下划线, 如 1_000_000
+func WithUnderscores() ParseOpt {
+	return func(o *ParseOptions) {
+		o.underscores = true
+	}
+}
+
+// WithBasePrefix 接受 0x/0o/0b 前缀的十六进制/八进制/二进制整数.
+// 不支持 C 风格的 0 前缀八进制, 010 仍为十进制的 10
+func WithBasePrefix() ParseOpt {
+	return func(o *ParseOptions) {
+		o.basePrefix = true
+	}
+}
+
+// WithScientific 整数类型也接受科学计数法和小数, 如 1.5e3;
+// 浮点数类型总是接受科学计数法
+func WithScientific() ParseOpt {
+	return func(o *ParseOptions) {
+		o.scientific = true
+	}
+}
+
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Uintptr: reflect.TypeOf(uintptr(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+// getNumber 解析整数和浮点数, 返回 Kind 对应的基础类型(别名类型由调用方转换)
+func (o *ParseOptions) getNumber(kind reflect.Kind, strVal string) (reflect.Value, error) {
+	typ := basicTypes[kind]
+	if strVal == "" {
+		return reflect.Zero(typ), nil
+	}
+
+	var v reflect.Value
+	var err error
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = o.parseInt(strVal, typ.Bits())
+		v = reflect.ValueOf(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, err = o.parseUint(strVal, typ.Bits())
+		v = reflect.ValueOf(u)
+	default:
+		var f float64
+		f, err = o.parseFloat(strVal, typ.Bits())
+		v = reflect.ValueOf(f)
+	}
+	if err != nil {
+		return reflect.Zero(typ), errors.Wrapf(err, "val=%s", strVal)
+	}
+	return v.Convert(typ), nil
+}
+
+func (o *ParseOptions) parseInt(strVal string, bitSize int) (int64, error) {
+	digits, base, err := o.normalizeNumber(strVal)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.ParseInt(digits, base, 64)
+	if err != nil {
+		if isRangeError(err) {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows int64", strVal)
+		}
+		if !o.scientific || base != 10 || !isDecimal(digits) {
+			return 0, err
+		}
+		bi, err := o.ratToInt(digits)
+		if err != nil {
+			return 0, err
+		}
+		if !bi.IsInt64() {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows int64", strVal)
+		}
+		i = bi.Int64()
+	}
+
+	if bitSize < 64 {
+		min, max := int64(-1)<<(bitSize-1), int64(1)<<(bitSize-1)-1
+		if i < min || i > max {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows int%d", strVal, bitSize)
+		}
+	}
+	return i, nil
+}
+
+func (o *ParseOptions) parseUint(strVal string, bitSize int) (uint64, error) {
+	digits, base, err := o.normalizeNumber(strVal)
+	if err != nil {
+		return 0, err
+	}
+	if strings.HasPrefix(digits, "-") {
+		// 负数只有值为 0(如 -0、非严格模式下的 -0.5)时合法, 其它合法的负数超出范围
+		i, err := o.parseInt(strVal, 64)
+		if err != nil && !errors.Is(err, ErrNumberOverflow) {
+			return 0, err
+		}
+		if err != nil || i < 0 {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows uint%d", strVal, bitSize)
+		}
+		return 0, nil
+	}
+	digits = strings.TrimPrefix(digits, "+")
+
+	u, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		if isRangeError(err) {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows uint64", strVal)
+		}
+		if !o.scientific || base != 10 || !isDecimal(digits) {
+			return 0, err
+		}
+		bi, err := o.ratToInt(digits)
+		if err != nil {
+			return 0, err
+		}
+		if !bi.IsUint64() {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows uint64", strVal)
+		}
+		u = bi.Uint64()
+	}
+
+	if bitSize < 64 && u > uint64(1)<<bitSize-1 {
+		return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows uint%d", strVal, bitSize)
+	}
+	return u, nil
+}
+
+// ratToInt 把小数/科学计数法转换为整数, 非严格模式下截断小数部分
+func (o *ParseOptions) ratToInt(digits string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(digits)
+	if !ok {
+		return nil, errors.Errorf("invalid number %s", digits)
+	}
+	if !r.IsInt() && o.strict {
+		return nil, errors.Wrapf(ErrNumberPrecision, "%s is not an integer", digits)
+	}
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+func (o *ParseOptions) parseFloat(strVal string, bitSize int) (float64, error) {
+	digits, base, err := o.normalizeNumber(strVal)
+	if err != nil {
+		return 0, err
+	}
+	if base != 10 {
+		i, err := strconv.ParseInt(digits, base, 64)
+		if err != nil {
+			if isRangeError(err) {
+				return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows int64", strVal)
+			}
+			return 0, err
+		}
+		digits = strconv.FormatInt(i, 10)
+	}
+
+	f, err := strconv.ParseFloat(digits, bitSize)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, errors.Wrapf(ErrNumberOverflow, "%s overflows float%d", strVal, bitSize)
+		}
+		return 0, err
+	}
+
+	if o.strict {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, errors.Wrapf(ErrNumberNotFinite, "val=%s", strVal)
+		}
+		if isInteger(digits) {
+			want, _ := new(big.Int).SetString(digits, 10)
+			got, _ := big.NewFloat(f).Int(nil)
+			if want.Cmp(got) != 0 {
+				return 0, errors.Wrapf(ErrNumberPrecision, "%s can not be represented by float%d", strVal, bitSize)
+			}
+		}
+	}
+	return f, nil
+}
+
+// normalizeNumber 去掉千分位分隔符、下划线和进制前缀, 返回带符号的数字及进制
+func (o *ParseOptions) normalizeNumber(strVal string) (string, int, error) {
+	s := strVal
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	base := 10
+	if o.basePrefix && len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			s = s[2:]
+		}
+	}
+
+	var err error
+	if o.underscores && strings.Contains(s, "_") {
+		if s, err = stripUnderscores(s, base); err != nil {
+			return "", 0, errors.Wrapf(err, "val=%s", strVal)
+		}
+	}
+	if o.thousandSep != 0 && base == 10 && strings.ContainsRune(s, o.thousandSep) {
+		if s, err = stripThousands(s, o.thousandSep); err != nil {
+			return "", 0, errors.Wrapf(err, "val=%s", strVal)
+		}
+	}
+	return sign + s, base, nil
+}
+
+// stripUnderscores 下划线只能出现在 base 进制的两个数字之间
+func stripUnderscores(s string, base int) (string, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(s)-1 || !isDigitOf(s[i-1], base) || !isDigitOf(s[i+1], base) {
+			return "", errors.Errorf("misplaced underscore at %d", i)
+		}
+	}
+	return strings.ReplaceAll(s, "_", ""), nil
+}
+
+// stripThousands 整数部分按三位分组, 第一组1~3位
+func stripThousands(s string, sep rune) (string, error) {
+	end := strings.IndexAny(s, ".eE")
+	if end < 0 {
+		end = len(s)
+	}
+	intPart, rest := s[:end], s[end:]
+	if strings.ContainsRune(rest, sep) {
+		return "", errors.Errorf("thousand separator %q after integer part", sep)
+	}
+
+	groups := strings.Split(intPart, string(sep))
+	for i, g := range groups {
+		if (i == 0 && (len(g) < 1 || len(g) > 3)) || (i > 0 && len(g) != 3) {
+			return "", errors.Errorf("invalid thousand separator grouping %s", intPart)
+		}
+	}
+	return strings.Join(groups, "") + rest, nil
+}
+
+// isDigitOf c 是否为 base(2/8/10/16)进制的数字
+func isDigitOf(c byte, base int) bool {
+	var d int
+	switch {
+	case '0' <= c && c <= '9':
+		d = int(c - '0')
+	case 'a' <= c && c <= 'f':
+		d = int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		d = int(c-'A') + 10
+	default:
+		return false
+	}
+	return d < base
+}
+
+// isRangeError strconv 的 ErrRange
+func isRangeError(err error) bool {
+	var numErr *strconv.NumError
+	return errors.As(err, &numErr) && numErr.Err == strconv.ErrRange
+}
+
+// isInteger 带可选符号的十进制整数
+func isInteger(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isDecimal 十进制小数或科学计数法, 如 -1.5e3, 不包括 NaN/Inf
+func isDecimal(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9') && c != '.' && c != 'e' && c != 'E' && c != '+' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
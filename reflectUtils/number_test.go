@@ -0,0 +1,250 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+type numberCase struct {
+	in   string
+	want interface{}
+	// err 期望的错误, nil 表示成功; errAny 表示任意错误
+	err error
+}
+
+var errAny = errors.New("any error")
+
+func checkNumbers(zero interface{}, cases []numberCase, opts ...ParseOpt) {
+	for _, c := range cases {
+		got, err := ParseStrToInstance(reflect.ValueOf(zero), c.in, opts...)
+		switch c.err {
+		case nil:
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, c.want)
+			So(got.Type(), ShouldEqual, reflect.TypeOf(zero))
+		case errAny:
+			So(err, ShouldNotBeNil)
+		default:
+			So(errors.Cause(err), ShouldEqual, c.err)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	Convey("lenient", t, func() {
+		Convey("int", func() {
+			checkNumbers(int(0), []numberCase{
+				{"", 0, nil},
+				{"-13", -13, nil},
+				{"+13", 13, nil},
+				{"9223372036854775807", math.MaxInt64, nil},
+				{"9223372036854775808", nil, ErrNumberOverflow},
+				{"-99999999999999999999", nil, ErrNumberOverflow},
+				{"1.5", nil, errAny},
+				{"1,000", nil, errAny},
+				{"0x10", nil, errAny},
+				{"abc", nil, errAny},
+			})
+		})
+		Convey("overflow", func() {
+			checkNumbers(uint8(0), []numberCase{{"300", nil, ErrNumberOverflow}, {"255", uint8(255), nil}})
+			checkNumbers(int8(0), []numberCase{{"128", nil, ErrNumberOverflow}, {"-128", int8(-128), nil}})
+			checkNumbers(int32(0), []numberCase{{"2147483648", nil, ErrNumberOverflow}})
+			checkNumbers(uint16(0), []numberCase{{"1.5e5", nil, ErrNumberOverflow}}, WithScientific())
+			checkNumbers(int64(0), []numberCase{{"99999999999999999999", nil, ErrNumberOverflow}})
+			checkNumbers(uint64(0), []numberCase{{"99999999999999999999", nil, ErrNumberOverflow}})
+			checkNumbers(uint(0), []numberCase{
+				{"-1", nil, ErrNumberOverflow},
+				{"-99999999999999999999", nil, ErrNumberOverflow},
+				{"-0", uint(0), nil},
+				{"+7", uint(7), nil},
+				{"-abc", nil, errAny},
+			})
+			checkNumbers(uint8(0), []numberCase{{"-1.5e3", nil, ErrNumberOverflow}, {"-0.5", uint8(0), nil}}, WithScientific())
+			checkNumbers(uint8(0), []numberCase{{"-0x10", nil, ErrNumberOverflow}}, WithBasePrefix())
+		})
+		Convey("float32", func() {
+			checkNumbers(float32(0), []numberCase{
+				{"", float32(0), nil},
+				{"17.23", float32(17.23), nil},
+				{"-2.5e3", float32(-2500), nil},
+				{"1e39", nil, ErrNumberOverflow},
+			})
+			got, err := ParseStrToInstance(reflect.ValueOf(float32(0)), "NaN")
+			So(err, ShouldBeNil)
+			So(math.IsNaN(float64(got.Interface().(float32))), ShouldBeTrue)
+		})
+		Convey("float64", func() {
+			checkNumbers(float64(0), []numberCase{
+				{"2.98109E+12", 2.98109e+12, nil},
+				{"Inf", math.Inf(1), nil},
+				{"9007199254740993", float64(9007199254740992), nil},
+				{"1e309", nil, ErrNumberOverflow},
+			})
+		})
+		Convey("uintptr", func() {
+			checkNumbers(uintptr(0), []numberCase{{"12", uintptr(12), nil}})
+		})
+		Convey("complex", func() {
+			checkNumbers(complex64(0), []numberCase{{"(1.5-2i)", complex64(complex(1.5, -2)), nil}, {"x", nil, errAny}})
+			checkNumbers(complex128(0), []numberCase{{"0.1+0.2i", complex(0.1, 0.2), nil}})
+		})
+		Convey("alias", func() {
+			type status uint8
+			checkNumbers(status(0), []numberCase{{"3", status(3), nil}})
+		})
+	})
+
+	Convey("strict", t, func() {
+		strict := WithStrict()
+
+		Convey("signed", func() {
+			checkNumbers(int8(0), []numberCase{
+				{"127", int8(127), nil},
+				{"-128", int8(-128), nil},
+				{"128", nil, ErrNumberOverflow},
+				{"-129", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(int16(0), []numberCase{
+				{"32767", int16(32767), nil},
+				{"32768", nil, ErrNumberOverflow},
+				{"-32769", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(int32(0), []numberCase{
+				{"-2147483648", int32(math.MinInt32), nil},
+				{"2147483648", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(int64(0), []numberCase{
+				{"-9223372036854775808", int64(math.MinInt64), nil},
+				{"-9223372036854775809", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(int(0), []numberCase{{"42", 42, nil}}, strict)
+		})
+
+		Convey("unsigned", func() {
+			checkNumbers(uint8(0), []numberCase{
+				{"255", uint8(255), nil},
+				{"300", nil, ErrNumberOverflow},
+				{"-1", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(uint16(0), []numberCase{
+				{"65535", uint16(65535), nil},
+				{"65536", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(uint32(0), []numberCase{
+				{"4294967295", uint32(math.MaxUint32), nil},
+				{"4294967296", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(uint64(0), []numberCase{
+				{"18446744073709551615", uint64(math.MaxUint64), nil},
+				{"18446744073709551616", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(uint(0), []numberCase{{"7", uint(7), nil}}, strict)
+		})
+
+		Convey("float", func() {
+			checkNumbers(float32(0), []numberCase{
+				{"16777216", float32(16777216), nil},
+				{"16777217", nil, ErrNumberPrecision},
+				{"0.1", float32(0.1), nil},
+				{"NaN", nil, ErrNumberNotFinite},
+				{"-Inf", nil, ErrNumberNotFinite},
+				{"3.5e38", nil, ErrNumberOverflow},
+			}, strict)
+			checkNumbers(float64(0), []numberCase{
+				{"9007199254740992", float64(9007199254740992), nil},
+				{"9007199254740993", nil, ErrNumberPrecision},
+				{"+Inf", nil, ErrNumberNotFinite},
+			}, strict)
+		})
+
+		Convey("alias", func() {
+			type status uint8
+			checkNumbers(status(0), []numberCase{{"256", nil, ErrNumberOverflow}}, strict)
+		})
+	})
+
+	Convey("formats", t, func() {
+		Convey("thousand separator", func() {
+			sep := WithThousandSeparator(',')
+			checkNumbers(int(0), []numberCase{
+				{"1,234,567", 1234567, nil},
+				{"-1,000", -1000, nil},
+				{"123", 123, nil},
+				{"1,23", nil, errAny},
+				{"1234,567", nil, errAny},
+				{",123", nil, errAny},
+			}, sep)
+			checkNumbers(float64(0), []numberCase{
+				{"1,234.5", 1234.5, nil},
+				{"1.234,5", nil, errAny},
+			}, sep)
+			checkNumbers(int(0), []numberCase{{"1 234", 1234, nil}}, WithThousandSeparator(' '))
+		})
+
+		Convey("underscores", func() {
+			checkNumbers(int(0), []numberCase{
+				{"1_000_000", 1000000, nil},
+				{"_1", nil, errAny},
+				{"1__0", nil, errAny},
+				{"1_", nil, errAny},
+				{"1_a", nil, errAny},
+			}, WithUnderscores())
+			checkNumbers(float64(0), []numberCase{
+				{"1_000.5", 1000.5, nil},
+				{"1_e5", nil, errAny},
+				{"1e_5", nil, errAny},
+			}, WithUnderscores())
+			checkNumbers(int(0), []numberCase{{"1_000", nil, errAny}})
+		})
+
+		Convey("base prefix", func() {
+			prefix := WithBasePrefix()
+			checkNumbers(int(0), []numberCase{
+				{"0x1F", 31, nil},
+				{"-0XfF", -255, nil},
+				{"0o17", 15, nil},
+				{"0b101", 5, nil},
+				{"010", 10, nil},
+				{"0x", nil, errAny},
+				{"0b2", nil, errAny},
+			}, prefix)
+			checkNumbers(uint8(0), []numberCase{
+				{"0xff", uint8(255), nil},
+				{"0x100", nil, ErrNumberOverflow},
+			}, prefix, WithStrict())
+			checkNumbers(int(0), []numberCase{
+				{"0xff_ff", 65535, nil},
+				{"0b1_1", 3, nil},
+				{"0b1_2", nil, errAny},
+				{"0o7_8", nil, errAny},
+			}, prefix, WithUnderscores())
+			checkNumbers(float64(0), []numberCase{{"0x10", float64(16), nil}}, prefix)
+		})
+
+		Convey("scientific", func() {
+			sci := WithScientific()
+			checkNumbers(int(0), []numberCase{
+				{"1e3", 1000, nil},
+				{"1.5E+3", 1500, nil},
+				{"-2.0", -2, nil},
+				{"1.5", 1, nil},
+				{"1e30", nil, ErrNumberOverflow},
+			}, sci)
+			checkNumbers(int(0), []numberCase{{"1.5", nil, ErrNumberPrecision}}, sci, WithStrict())
+			checkNumbers(uint16(0), []numberCase{
+				{"6.5e4", uint16(65000), nil},
+				{"7e4", nil, ErrNumberOverflow},
+			}, sci, WithStrict())
+			checkNumbers(int(0), []numberCase{{"1,500e2", 150000, nil}}, sci, WithThousandSeparator(','))
+		})
+	})
+
+	Convey("float32 round trip", t, func() {
+		So(quick.Check(roundTrip[float32], nil), ShouldBeNil)
+	})
+}
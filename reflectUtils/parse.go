@@ -13,22 +13,8 @@ import (
 // 将结构体的所有字段都返回(会将 Anonymous fields 打平后包含在内)
 // 参考: https://stackoverflow.com/questions/24333494/golang-reflection-on-embedded-structs
 func FlatStructFields(anonymousField interface{}) []reflect.StructField {
-	fields := make([]reflect.StructField, 0)
-	ifv := reflect.ValueOf(anonymousField)
-	ift := reflect.TypeOf(anonymousField)
-
-	for i := 0; i < ift.NumField(); i++ {
-		fv := ifv.Field(i)
-		ft := ift.Field(i)
-		if ft.Type.Kind() == reflect.Struct && ft.Anonymous {
-			fields = append(fields, FlatStructFields(fv.Interface())...)
-		} else {
-			fields = append(fields, ft)
-		}
-
-	}
-
-	return fields
+	flat := CachedFields(reflect.TypeOf(anonymousField), "").flat
+	return append(make([]reflect.StructField, 0, len(flat)), flat...)
 }
 
 func isAliasType(zeroVal reflect.Value) bool {
@@ -41,47 +27,12 @@ func isAliasType(zeroVal reflect.Value) bool {
 	return underlying.String() != instanceType.String()
 }
 
+// getTimeFromStr 使用默认的 TimeParser
 func getTimeFromStr(strVal string) (time.Time, error) {
-	if strVal == "" || strVal == "0000-00-00 00:00:00" {
-		return time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local), nil
-	}
-
-	t, err := time.ParseInLocation("2006-01-02 15:04:05", strVal, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	t, err = time.ParseInLocation("2006/1/2 5:4:5", strVal, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	t, err = time.ParseInLocation("01/02/06 15:04", strVal, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	t, err = time.ParseInLocation("2006/01/02 15:04:05", strVal, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	t, err = time.ParseInLocation("1/2/06 15:4", strVal, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	// 2023-07-04T09:36:33
-	t, err = time.ParseInLocation("2006-01-02T15:04:05", strVal, time.Local)
-	if err == nil {
-
-		return t, nil
-	}
-	// 2023-07-04T09:36:33.2961605775
-	t, err = time.ParseInLocation("2006-01-02T15:14:15.0000000000", strVal, time.Local)
-	if err == nil {
-		return t, nil
-	}
-
-	return time.Date(1971, 1, 1, 0, 0, 0, 0, time.Local), nil
-
+	return (&TimeParser{}).Parse(strVal)
 }
-func getInstanceOfAliasType(aliasTypeZeroVal reflect.Value, strVal string) (reflect.Value, error) {
+
+func (o *ParseOptions) getInstanceOfAliasType(aliasTypeZeroVal reflect.Value, strVal string) (reflect.Value, error) {
 
 	underlyingKind := aliasTypeZeroVal.Kind()
 	switch underlyingKind {
@@ -89,7 +40,7 @@ func getInstanceOfAliasType(aliasTypeZeroVal reflect.Value, strVal string) (refl
 		if !json.Valid([]byte(strVal)) { // 无效json
 			switch aliasTypeZeroVal.Type().Name() {
 			case "Time":
-				t, err := getTimeFromStr(strVal)
+				t, err := o.parseTime(strVal)
 				if err != nil {
 					return reflect.ValueOf(t), errors.Wrapf(err, "ParseInLocation(%s)", strVal)
 				}
@@ -98,18 +49,17 @@ func getInstanceOfAliasType(aliasTypeZeroVal reflect.Value, strVal string) (refl
 				return reflect.ValueOf(""), fmt.Errorf("strVal(%s) not json-format", strVal)
 			}
 		} else { // 有效json
-			v := reflect.New(aliasTypeZeroVal.Type())
-			if strVal != "" {
-				err := json.Unmarshal([]byte(strVal), v.Interface())
-				if err != nil {
-					return reflect.Value{}, errors.Wrapf(err, "json val=%s", strVal)
-				}
+			if strVal == "" {
+				return reflect.New(aliasTypeZeroVal.Type()).Elem(), nil
 			}
-
-			return v.Elem(), nil
+			v, err := unmarshalJSON(aliasTypeZeroVal.Type(), []byte(strVal))
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "json val=%s", strVal)
+			}
+			return v, nil
 		}
 	default:
-		v, err := getInstance(aliasTypeZeroVal, strVal)
+		v, err := o.getInstance(aliasTypeZeroVal, strVal)
 		if err != nil {
 			return aliasTypeZeroVal, errors.Wrapf(err, "getInstance(%v,%s)", aliasTypeZeroVal, strVal)
 		}
@@ -118,157 +68,41 @@ func getInstanceOfAliasType(aliasTypeZeroVal reflect.Value, strVal string) (refl
 
 }
 
-func getInstance(instanceZeroVal reflect.Value, strVal string) (reflect.Value, error) {
+func (o *ParseOptions) getInstance(instanceZeroVal reflect.Value, strVal string) (reflect.Value, error) {
 	underlyingKind := instanceZeroVal.Kind()
 
 	//var x reflect.Value
 	switch underlyingKind {
 	case reflect.String:
 		return reflect.ValueOf(strVal), nil
-	case reflect.Int:
-		if strVal == "" {
-			return reflect.ValueOf(0), nil
-		}
-		i, err := strconv.ParseInt(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(0), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(int(i)), nil
-	case reflect.Int8:
-		if strVal == "" {
-			return reflect.ValueOf(int8(0)), nil
-		}
-		i, err := strconv.ParseInt(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(int8(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(int8(i)), nil
-	case reflect.Int16:
-		if strVal == "" {
-			return reflect.ValueOf(int16(0)), nil
-		}
-		i, err := strconv.ParseInt(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(int16(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(int16(i)), nil
-	case reflect.Int32:
-		if strVal == "" {
-			return reflect.ValueOf(int32(0)), nil
-		}
-		i, err := strconv.ParseInt(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(int32(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(int32(i)), nil
-	case reflect.Int64:
-		if strVal == "" {
-			return reflect.ValueOf(int64(0)), nil
-		}
-		i, err := strconv.ParseInt(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(int64(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(i), nil
-	case reflect.Uint:
-		if strVal == "" {
-			return reflect.ValueOf(uint(0)), nil
-		}
-		i, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(uint(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(uint(i)), nil
-	case reflect.Uint8:
-		if strVal == "" {
-			return reflect.ValueOf(uint8(0)), nil
-		}
-		i, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(uint8(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(uint8(i)), nil
-	case reflect.Uint16:
-		if strVal == "" {
-			return reflect.ValueOf(uint16(0)), nil
-		}
-		i, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(uint16(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(uint16(i)), nil
-	case reflect.Uint32:
-		if strVal == "" {
-			return reflect.ValueOf(uint32(0)), nil
-		}
-		i, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(uint32(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(uint32(i)), nil
-	case reflect.Uint64:
-		if strVal == "" {
-			return reflect.ValueOf(uint64(0)), nil
-		}
-		i, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			return reflect.ValueOf(uint64(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(i), nil
-	case reflect.Uintptr:
-		if strVal == "" {
-			dummy := uint(0)
-			return reflect.ValueOf(&dummy), nil
-		}
-		i, err := strconv.ParseUint(strVal, 10, 64)
-		if err != nil {
-			dummy := uint(0)
-			return reflect.ValueOf(&dummy), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(&i), nil
-	case reflect.Float32:
-		if strVal == "" {
-			return reflect.ValueOf(float32(0)), nil
-		}
-		i, err := strconv.ParseFloat(strVal, 64)
-		if err == nil {
-			return reflect.ValueOf(float32(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(float32(i)), nil
-
-	case reflect.Float64:
-		if strVal == "" {
-			return reflect.ValueOf(float64(0)), nil
-		}
-		i, err := strconv.ParseFloat(strVal, 64)
-		if err != nil {
-			return reflect.ValueOf(float64(0)), errors.Wrapf(err, "val=%s", strVal)
-		}
-		return reflect.ValueOf(i), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return o.getNumber(underlyingKind, strVal)
 	case reflect.Complex64:
 		if strVal == "" {
 			return reflect.ValueOf(complex64(0)), nil
 		}
 		i, err := strconv.ParseComplex(strVal, 64)
 		if err != nil {
-			return reflect.ValueOf(strVal), errors.Wrapf(err, "val=%s", strVal)
+			return reflect.ValueOf(complex64(0)), errors.Wrapf(err, "val=%s", strVal)
 		}
 		return reflect.ValueOf(complex64(i)), nil
 	case reflect.Complex128:
 		if strVal == "" {
 			return reflect.ValueOf(complex128(0)), nil
 		}
-		i, err := strconv.ParseComplex(strVal, 64)
+		i, err := strconv.ParseComplex(strVal, 128)
 		if err != nil {
-			return reflect.ValueOf(strVal), errors.Wrapf(err, "val=%s", strVal)
+			return reflect.ValueOf(complex128(0)), errors.Wrapf(err, "val=%s", strVal)
 		}
 		return reflect.ValueOf(i), nil
 	case reflect.Bool:
-		if strVal == "true" {
-			return reflect.ValueOf(true), nil
-		} else {
-			return reflect.ValueOf(false), nil
+		b, err := o.parseBool(strVal)
+		if err != nil {
+			return reflect.ValueOf(false), err
 		}
+		return reflect.ValueOf(b), nil
 	case reflect.Struct:
 		if strVal == "" {
 			v := reflect.New(instanceZeroVal.Type())
@@ -276,33 +110,17 @@ func getInstance(instanceZeroVal reflect.Value, strVal string) (reflect.Value, e
 		}
 
 		if false { // isAliasType(instanceZeroVal)
-			return getInstanceOfAliasType(instanceZeroVal, strVal)
+			return o.getInstanceOfAliasType(instanceZeroVal, strVal)
 		} else {
-			v := reflect.New(instanceZeroVal.Type())
-			if strVal != "" {
-				err := json.Unmarshal([]byte(strVal), v.Interface())
-				if err != nil {
-					return reflect.Value{}, errors.Wrapf(err, "json val=%s", strVal)
-				}
-			}
-
-			return v.Elem(), nil
-		}
-
-	case reflect.Map, reflect.Slice, reflect.Array: // strVal should be json
-		v := reflect.New(instanceZeroVal.Type())
-
-		if strVal == "" {
-			return v.Elem(), nil
-		}
-		if json.Valid([]byte(strVal)) {
-			err := json.Unmarshal([]byte(strVal), v.Interface())
+			v, err := unmarshalJSON(instanceZeroVal.Type(), []byte(strVal))
 			if err != nil {
-				return reflect.Value{}, errors.Wrapf(err, "val=%s", strVal)
+				return reflect.Value{}, errors.Wrapf(err, "json val=%s", strVal)
 			}
+			return v, nil
 		}
 
-		return v.Elem(), nil
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return o.parseCollection(instanceZeroVal, strVal)
 
 	case reflect.Pointer:
 		if strVal == "" || strVal == "null" {
@@ -311,12 +129,13 @@ func getInstance(instanceZeroVal reflect.Value, strVal string) (reflect.Value, e
 
 		instanceVal := instanceZeroVal.Elem()
 		if !instanceVal.IsValid() {
-			return instanceZeroVal, nil
+			// nil 指针, 按元素类型的零值解析
+			instanceVal = reflect.Zero(instanceZeroVal.Type().Elem())
 		}
 
 		instanceType := instanceVal.Type()
 
-		gotVal, err := ParseStrToInstance(instanceVal, strVal)
+		gotVal, err := o.parse(instanceVal, strVal)
 
 		if err == nil {
 			fieldPtr := reflect.New(instanceType)
@@ -337,6 +156,17 @@ func getInstance(instanceZeroVal reflect.Value, strVal string) (reflect.Value, e
 // ParseStrToInstance
 // 把字符串翻译为指定类型
 //
+// 解析顺序:
+// 1. WithConverter/WithConverters 注册的解析函数
+// 2. RegisterConverter 注册的全局解析函数
+// 3. time.Time: WithTimeParser 指定的 TimeParser; time.Duration: time.ParseDuration
+// 4. 实现了 encoding.TextUnmarshaler/json.Unmarshaler 的类型
+// 5. 按 Kind 解析
+//
+// RegisterVariant 注册了实现的接口类型: 按 json 中的判别字段选择实现类型, 嵌套在 struct/map/slice/array 中时同样处理
+// slice/array/map: json, 或 WithListSeparator/WithMapSeparators 指定的分隔格式(如 a,b,c、k1=v1;k2=v2),
+// 都不符合时返回 ErrMalformed
+//
 // supported:
 // struct
 // standard type(int,string,bool...)
@@ -344,20 +174,45 @@ func getInstance(instanceZeroVal reflect.Value, strVal string) (reflect.Value, e
 // not supported:
 // reflect.Chan
 // reflect.Func
-// reflect.Interface
+// reflect.Interface(RegisterVariant 注册了实现的除外)
 // reflect.UnsafePointer
-func ParseStrToInstance(zeroVal reflect.Value, strVal string) (reflect.Value, error) {
+func ParseStrToInstance(zeroVal reflect.Value, strVal string, opts ...ParseOpt) (reflect.Value, error) {
+	return newParseOptions(opts).parse(zeroVal, strVal)
+}
 
-	var instance reflect.Value
-	var err error
+func (o *ParseOptions) parse(zeroVal reflect.Value, strVal string) (reflect.Value, error) {
+	if v, ok, err := o.convert(zeroVal.Type(), strVal); ok {
+		return v, err
+	}
+	return o.parseBuiltin(zeroVal, strVal)
+}
 
-	if isAliasType(zeroVal) {
-		return getInstanceOfAliasType(zeroVal, strVal)
-	} else {
-		instance, err = getInstance(zeroVal, strVal)
+// parseBuiltin 不使用注册的解析函数
+func (o *ParseOptions) parseBuiltin(zeroVal reflect.Value, strVal string) (reflect.Value, error) {
+	typ := zeroVal.Type()
+	if typ == timeType {
+		t, err := o.parseTime(strVal)
 		if err != nil {
-			return instance, err
+			return reflect.ValueOf(t), err
 		}
-		return instance, err
+		return reflect.ValueOf(t), nil
+	}
+	if typ == durationType {
+		d, err := o.parseDuration(strVal)
+		if err != nil {
+			return reflect.ValueOf(d), err
+		}
+		return reflect.ValueOf(d), nil
+	}
+	if unmarshaler(typ) {
+		return unmarshal(typ, strVal)
+	}
+	if typ.Kind() == reflect.Interface {
+		return parseVariant(typ, strVal)
+	}
+
+	if isAliasType(zeroVal) {
+		return o.getInstanceOfAliasType(zeroVal, strVal)
 	}
+	return o.getInstance(zeroVal, strVal)
 }
@@ -48,9 +48,9 @@ func Test_convertAliasType(t *testing.T) {
 			strVal := "8"
 			var val uint8
 			valI := reflect.ValueOf(val)
-			instance, err := getInstance(valI, strVal)
+			instance, err := newParseOptions(nil).getInstance(valI, strVal)
 
-			gotI, err := getInstanceOfAliasType(instance, strVal)
+			gotI, err := newParseOptions(nil).getInstanceOfAliasType(instance, strVal)
 			So(err, ShouldBeNil)
 			got := gotI.Interface()
 			_, assertOk := got.(uint8)
@@ -64,7 +64,7 @@ func Test_convertAliasType(t *testing.T) {
 
 			var i int8Alias
 			v := reflect.ValueOf(i)
-			gotI, err := getInstanceOfAliasType(v, strVal)
+			gotI, err := newParseOptions(nil).getInstanceOfAliasType(v, strVal)
 			So(err, ShouldBeNil)
 			got := gotI.Interface()
 			_, assertOk := got.(int8Alias)
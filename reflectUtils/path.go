@@ -0,0 +1,389 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrPathNotFound 路径中的字段、下标或 map key 不存在, 或经过了 nil 指针
+var ErrPathNotFound = errors.New("path not found")
+
+// DefaultMaxPathIndex 按路径赋值时 slice 默认允许扩展到的最大下标, 避免 tags[2000000000] 这样的输入分配过大的内存
+const DefaultMaxPathIndex = 10000
+
+// DefaultPathTag Get/Set 默认按该 tag 的名字查找字段, 找不到时再按字段名查找
+const DefaultPathTag = "json"
+
+// PathOptions Get/Set/Unflatten 按路径访问时的选项
+type PathOptions struct {
+	// maxIndex 按路径赋值时 slice 允许扩展到的最大下标, 为 0 时使用 DefaultMaxPathIndex
+	maxIndex int
+	// tag Get/Set 查找字段的 tag, 为 nil 时使用 DefaultPathTag
+	tag *string
+	// parse Set/Unflatten 解析字符串的选项
+	parse *ParseOptions
+}
+
+type PathOpt func(o *PathOptions)
+
+func newPathOptions(opts []PathOpt) *PathOptions {
+	o := &PathOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.parse == nil {
+		o.parse = newParseOptions(nil)
+	}
+	return o
+}
+
+// WithMaxPathIndex Unflatten/Set 扩展 slice 时允许的最大下标, 超过时返回错误
+func WithMaxPathIndex(n int) PathOpt {
+	return func(o *PathOptions) {
+		o.maxIndex = n
+	}
+}
+
+// WithPathTag Get/Set 按 tag 的名字查找字段, 为空时只按字段名查找
+func WithPathTag(tag string) PathOpt {
+	return func(o *PathOptions) {
+		o.tag = &tag
+	}
+}
+
+// WithPathParseOpts Set/Unflatten 解析字符串时使用的选项, 同 ParseStrToInstance
+func WithPathParseOpts(opts ...ParseOpt) PathOpt {
+	return func(o *PathOptions) {
+		o.parse = newParseOptions(opts)
+	}
+}
+
+// Get 按路径取值, 如 order.items[2].price; obj 可以是结构体、map、slice 或它们的指针,
+// 名字按 DefaultPathTag(或 WithPathTag 指定的 tag)的名字或字段名查找
+func Get(obj interface{}, path string, opts ...PathOpt) (interface{}, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	o := newPathOptions(opts)
+	v, err := o.getPath(reflect.ValueOf(obj), segs, o.tagName())
+	if err != nil {
+		return nil, errors.Wrapf(err, "get %s", path)
+	}
+	return v.Interface(), nil
+}
+
+// Set 按路径把 strVal 用 ParseStrToInstance 解析后赋值, objPtr 必须是非 nil 指针;
+// 途经的 nil 指针/map 会被分配, slice 按下标扩展(下标不超过 WithMaxPathIndex)
+func Set(objPtr interface{}, path string, strVal string, opts ...PathOpt) error {
+	v := reflect.ValueOf(objPtr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.Errorf("set: objPtr must be a non-nil pointer, got %T", objPtr)
+	}
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	o := newPathOptions(opts)
+	if err := o.setPath(v.Elem(), segs, o.tagName(), o.parse.parseTo(strVal)); err != nil {
+		return errors.Wrapf(err, "set %s", path)
+	}
+	return nil
+}
+
+// pathSeg 路径中的一段: 字段名/map key, 或 [n] 下标
+type pathSeg struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+func (s pathSeg) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	return s.name
+}
+
+// parsePath 解析 address.city、items[2].price、[0]、labels["a.b"] 形式的路径,
+// 名字中不能包含 . [ ] ", 否则使用 ["..."] 的形式, 引号内按 Go 字符串转义
+func parsePath(path string) ([]pathSeg, error) {
+	segs := make([]pathSeg, 0, 4)
+	rest := path
+	// afterDot 名字只能出现在开头或 . 之后
+	afterDot := true
+	for rest != "" {
+		switch rest[0] {
+		case '[':
+			if len(rest) > 1 && rest[1] == '"' {
+				name, n, err := unquoteKey(rest[1:])
+				if err != nil {
+					return nil, errors.Wrapf(err, "path %s", path)
+				}
+				segs = append(segs, pathSeg{name: name})
+				rest = rest[1+n:]
+				afterDot = false
+				continue
+			}
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.Errorf("path %s: missing ]", path)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil || n < 0 {
+				return nil, errors.Errorf("path %s: invalid index %s", path, rest[1:end])
+			}
+			segs = append(segs, pathSeg{index: n, isIndex: true})
+			rest = rest[end+1:]
+			afterDot = false
+		case '.':
+			if len(segs) == 0 || rest == "." {
+				return nil, errors.Errorf("path %s: unexpected .", path)
+			}
+			rest = rest[1:]
+			afterDot = true
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, errors.Errorf("path %s: empty name", path)
+			}
+		default:
+			if !afterDot {
+				return nil, errors.Errorf("path %s: unexpected %s", path, rest)
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if rest[:end] == "" || strings.ContainsRune(rest[:end], ']') {
+				return nil, errors.Errorf("path %s: invalid name %s", path, rest[:end])
+			}
+			segs = append(segs, pathSeg{name: rest[:end]})
+			rest = rest[end:]
+			afterDot = false
+		}
+	}
+	if len(segs) == 0 {
+		return nil, errors.Errorf("empty path")
+	}
+	return segs, nil
+}
+
+// unquoteKey 解析 "..."] 形式的 key, 返回 key 和包括 ] 在内的长度
+func unquoteKey(s string) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			key, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", 0, errors.Errorf("invalid key %s", s[:i+1])
+			}
+			if i+1 >= len(s) || s[i+1] != ']' {
+				return "", 0, errors.Errorf("missing ] after %s", s[:i+1])
+			}
+			return key, i + 2, nil
+		}
+	}
+	return "", 0, errors.Errorf("unterminated key %s", s)
+}
+
+// keyPath 在 prefix 后加上 map key, key 为空或含有 . [ ] " 时使用 ["..."] 的形式
+func keyPath(prefix, key string) string {
+	if key == "" || strings.ContainsAny(key, `.[]"`) {
+		return prefix + "[" + strconv.Quote(key) + "]"
+	}
+	return joinPath(prefix, key)
+}
+
+// fieldByName 按 tag 名字或字段名查找导出字段(含打平的匿名结构体字段), tag 优先
+func fieldByName(typ reflect.Type, tag, name string) (*FieldInfo, bool) {
+	fields := CachedFields(typ, tag).Fields
+	for _, f := range fields {
+		if !f.Skip && f.Tagged && f.Name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if !f.Skip && f.Field.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func (o *PathOptions) tagName() string {
+	if o.tag != nil {
+		return *o.tag
+	}
+	return DefaultPathTag
+}
+
+// getPath 沿 segs 取值, 不分配任何值
+func (o *PathOptions) getPath(v reflect.Value, segs []pathSeg, tag string) (reflect.Value, error) {
+	for _, seg := range segs {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, errors.Wrapf(ErrPathNotFound, "%s: nil %s", seg, v.Type())
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			if seg.isIndex {
+				return reflect.Value{}, errors.Errorf("%s: %s is not a slice", seg, v.Type())
+			}
+			sf, ok := fieldByName(v.Type(), tag, seg.name)
+			if !ok {
+				return reflect.Value{}, errors.Wrapf(ErrPathNotFound, "%s: no such field in %s", seg, v.Type())
+			}
+			v = v.FieldByIndex(sf.Index)
+		case reflect.Slice, reflect.Array:
+			if !seg.isIndex {
+				return reflect.Value{}, errors.Errorf("%s: %s is not a struct or map", seg, v.Type())
+			}
+			if seg.index >= v.Len() {
+				return reflect.Value{}, errors.Wrapf(ErrPathNotFound, "%s: index out of range %d", seg, v.Len())
+			}
+			v = v.Index(seg.index)
+		case reflect.Map:
+			key, err := o.parse.mapKey(v.Type(), seg)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			ev := v.MapIndex(key)
+			if !ev.IsValid() {
+				return reflect.Value{}, errors.Wrapf(ErrPathNotFound, "%s: no such key", seg)
+			}
+			v = ev
+		default:
+			return reflect.Value{}, errors.Errorf("%s: %s has no fields", seg, v.Type())
+		}
+	}
+	return v, nil
+}
+
+// parseTo 把 strVal 解析后赋值给 v, 空 interface 直接赋值字符串
+func (o *ParseOptions) parseTo(strVal string) func(v reflect.Value) error {
+	return func(v reflect.Value) error {
+		if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(strVal))
+			return nil
+		}
+		nv, err := o.parse(reflect.Zero(v.Type()), strVal)
+		if err != nil {
+			return err
+		}
+		v.Set(assignable(nv, v.Type()))
+		return nil
+	}
+}
+
+// setPath 沿 segs 找到目标并调用 set, v 必须可 Set.
+// nil 指针/map 会被分配, slice 长度不足时扩展, interface 中间节点使用 map[string]interface{}/[]interface{}
+func (o *PathOptions) setPath(v reflect.Value, segs []pathSeg, tag string, set func(v reflect.Value) error) error {
+	if len(segs) == 0 {
+		return set(v)
+	}
+
+	seg := segs[0]
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return o.setPath(v.Elem(), segs, tag, set)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return errors.Errorf("%s: can not set through %s", seg, v.Type())
+		}
+		// 复制为可 Set 的值, 修改后写回
+		var cp reflect.Value
+		switch {
+		case !v.IsNil():
+			cp = reflect.New(v.Elem().Type()).Elem()
+			cp.Set(v.Elem())
+		case seg.isIndex:
+			cp = reflect.New(reflect.TypeOf([]interface{}{})).Elem()
+		default:
+			cp = reflect.New(reflect.TypeOf(map[string]interface{}{})).Elem()
+		}
+		if err := o.setPath(cp, segs, tag, set); err != nil {
+			return err
+		}
+		v.Set(cp)
+		return nil
+	case reflect.Struct:
+		if seg.isIndex {
+			return errors.Errorf("%s: %s is not a slice", seg, v.Type())
+		}
+		sf, ok := fieldByName(v.Type(), tag, seg.name)
+		if !ok {
+			return errors.Wrapf(ErrPathNotFound, "%s: no such field in %s", seg, v.Type())
+		}
+		return o.setPath(v.FieldByIndex(sf.Index), segs[1:], tag, set)
+	case reflect.Slice:
+		if !seg.isIndex {
+			return errors.Errorf("%s: %s is not a struct or map", seg, v.Type())
+		}
+		if seg.index >= v.Len() {
+			if max := o.maxPathIndex(); seg.index > max {
+				return errors.Errorf("%s: index exceeds max %d", seg, max)
+			}
+			grown := reflect.MakeSlice(v.Type(), seg.index+1, seg.index+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+		return o.setPath(v.Index(seg.index), segs[1:], tag, set)
+	case reflect.Array:
+		if !seg.isIndex {
+			return errors.Errorf("%s: %s is not a struct or map", seg, v.Type())
+		}
+		if seg.index >= v.Len() {
+			return errors.Wrapf(ErrPathNotFound, "%s: index out of range %d", seg, v.Len())
+		}
+		return o.setPath(v.Index(seg.index), segs[1:], tag, set)
+	case reflect.Map:
+		key, err := o.parse.mapKey(v.Type(), seg)
+		if err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// map 元素不可寻址, 复制后写回
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if old := v.MapIndex(key); old.IsValid() {
+			elem.Set(old)
+		}
+		if err := o.setPath(elem, segs[1:], tag, set); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	default:
+		return errors.Errorf("%s: %s has no fields", seg, v.Type())
+	}
+}
+
+func (o *PathOptions) maxPathIndex() int {
+	if o.maxIndex > 0 {
+		return o.maxIndex
+	}
+	return DefaultMaxPathIndex
+}
+
+// mapKey 把路径中的一段解析为 map 的 key
+func (o *ParseOptions) mapKey(mapType reflect.Type, seg pathSeg) (reflect.Value, error) {
+	keyType := mapType.Key()
+	key, err := o.parse(reflect.Zero(keyType), seg.String())
+	if seg.isIndex && keyType.Kind() != reflect.String {
+		key, err = o.parse(reflect.Zero(keyType), strconv.Itoa(seg.index))
+	}
+	if err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "map key %s", seg)
+	}
+	return assignable(key, keyType), nil
+}
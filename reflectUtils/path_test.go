@@ -0,0 +1,149 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+type pathItem struct {
+	Sku   string  `json:"sku"`
+	Price float64 `json:"price"`
+}
+
+type pathOrder struct {
+	bindBase
+	Items    []pathItem        `json:"items"`
+	Customer *flatAddress      `json:"customer"`
+	Limits   [2]int            `json:"limits"`
+	Meta     map[string]string `json:"meta"`
+	Counts   map[int]*pathItem `json:"counts"`
+	Any      interface{}       `json:"any"`
+	Refs     map[string][]int64
+	Hidden   string `json:"-"`
+}
+
+func TestParsePath(t *testing.T) {
+	Convey("parsePath", t, func() {
+		segs, err := parsePath("order.items[2][3].price")
+		So(err, ShouldBeNil)
+		So(segs, ShouldResemble, []pathSeg{
+			{name: "order"}, {name: "items"}, {index: 2, isIndex: true}, {index: 3, isIndex: true}, {name: "price"},
+		})
+
+		segs, err = parsePath("[0].a")
+		So(err, ShouldBeNil)
+		So(segs, ShouldResemble, []pathSeg{{index: 0, isIndex: true}, {name: "a"}})
+
+		segs, err = parsePath(`m["a.b"]["x]\"y"].c`)
+		So(err, ShouldBeNil)
+		So(segs, ShouldResemble, []pathSeg{{name: "m"}, {name: "a.b"}, {name: `x]"y`}, {name: "c"}})
+
+		for _, bad := range []string{"", ".a", "a.", "a..b", "a[", "a[x]", "a[-1]", "a[0]b", "a.[0]", "a]b",
+			`a["b]`, `a["b"`, `a["b"x]`, `a["\q"]`} {
+			_, err := parsePath(bad)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestGetSet(t *testing.T) {
+	Convey("Get", t, func() {
+		o := pathOrder{
+			bindBase: bindBase{Id: 3},
+			Items:    []pathItem{{Sku: "a", Price: 1}, {Sku: "b", Price: 2.5}},
+			Meta:     map[string]string{"src": "web"},
+			Counts:   map[int]*pathItem{1: {Sku: "c"}},
+			Any:      map[string]interface{}{"k": []interface{}{"v"}},
+		}
+
+		for path, want := range map[string]interface{}{
+			"id":             3,
+			"Id":             3,
+			"items[1].price": 2.5,
+			"Items[1].Sku":   "b",
+			"limits[1]":      0,
+			"meta.src":       "web",
+			"counts.1.sku":   "c",
+			"counts[1].sku":  "c",
+			"any.k[0]":       "v",
+		} {
+			got, err := Get(&o, path)
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, want)
+		}
+
+		got, err := Get(o, "items[0]")
+		So(err, ShouldBeNil)
+		So(got, ShouldResemble, pathItem{Sku: "a", Price: 1})
+
+		for _, path := range []string{"items[2].price", "customer.city", "meta.x", "nope", "limits[2]"} {
+			_, err := Get(o, path)
+			So(errors.Is(err, ErrPathNotFound), ShouldBeTrue)
+		}
+		_, err = Get(o, "id.x")
+		So(err, ShouldNotBeNil)
+		So(errors.Is(err, ErrPathNotFound), ShouldBeFalse)
+	})
+
+	Convey("Set", t, func() {
+		var o pathOrder
+		So(Set(&o, "items[2].price", "9.5"), ShouldBeNil)
+		So(o.Items, ShouldResemble, []pathItem{{}, {}, {Price: 9.5}})
+
+		So(Set(&o, "customer.city", "Hangzhou"), ShouldBeNil)
+		So(o.Customer, ShouldResemble, &flatAddress{City: "Hangzhou"})
+
+		So(Set(&o, "Id", "1,000", WithPathParseOpts(WithThousandSeparator(','))), ShouldBeNil)
+		So(o.Id, ShouldEqual, 1000)
+
+		So(Set(&o, "limits[1]", "4"), ShouldBeNil)
+		So(o.Limits, ShouldEqual, [2]int{0, 4})
+
+		So(Set(&o, "meta.src", "app"), ShouldBeNil)
+		So(o.Meta, ShouldResemble, map[string]string{"src": "app"})
+
+		So(Set(&o, "counts.2.sku", "x"), ShouldBeNil)
+		So(Set(&o, "counts.2.price", "1"), ShouldBeNil)
+		So(o.Counts[2], ShouldResemble, &pathItem{Sku: "x", Price: 1})
+
+		So(Set(&o, "Refs.a[1]", "7"), ShouldBeNil)
+		So(o.Refs, ShouldResemble, map[string][]int64{"a": {0, 7}})
+
+		So(Set(&o, "any.k[1]", "v"), ShouldBeNil)
+		So(o.Any, ShouldResemble, map[string]interface{}{"k": []interface{}{nil, "v"}})
+
+		// 空字符串把指针置为 nil
+		So(Set(&o, "customer", ""), ShouldBeNil)
+		So(o.Customer, ShouldBeNil)
+
+		So(errors.Is(Set(&o, "limits[2]", "1"), ErrPathNotFound), ShouldBeTrue)
+		So(errors.Is(Set(&o, "nope", "1"), ErrPathNotFound), ShouldBeTrue)
+		So(errors.Is(Set(&o, "Hidden", "1"), ErrPathNotFound), ShouldBeTrue)
+		So(Set(&o, "id", "x"), ShouldNotBeNil)
+		So(Set(o, "id", "1"), ShouldNotBeNil)
+
+		// 下标超过上限时不分配
+		So(Set(&o, "items[2000000000].price", "1"), ShouldNotBeNil)
+		So(len(o.Items), ShouldEqual, 3)
+		So(Set(&o, "items[4].price", "1", WithMaxPathIndex(3)), ShouldNotBeNil)
+	})
+
+	Convey("WithPathTag", t, func() {
+		type tagged struct {
+			Name string `yaml:"full_name" json:"name"`
+		}
+		var v tagged
+		So(Set(&v, "full_name", "a", WithPathTag("yaml")), ShouldBeNil)
+		So(v.Name, ShouldEqual, "a")
+		So(errors.Is(Set(&v, "name", "b", WithPathTag("yaml")), ErrPathNotFound), ShouldBeTrue)
+		So(errors.Is(Set(&v, "name", "b", WithPathTag("")), ErrPathNotFound), ShouldBeTrue)
+
+		got, err := Get(v, "full_name", WithPathTag("yaml"))
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "a")
+		got, err = Get(v, "Name", WithPathTag(""))
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "a")
+	})
+}
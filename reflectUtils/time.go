@@ -0,0 +1,139 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ErrTimeFormat 时间字符串不匹配任何格式
+var ErrTimeFormat = errors.New("unrecognized time format")
+
+// UnixUnit 纯数字时间戳的单位
+type UnixUnit int
+
+const (
+	// UnixNone 不把纯数字作为时间戳
+	UnixNone UnixUnit = iota
+	UnixSeconds
+	UnixMillis
+)
+
+// DefaultTimeLayouts TimeParser 默认按顺序尝试的格式
+var DefaultTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006/1/2 15:4:5",
+	"01/02/06 15:04",
+	"2006/01/02 15:04:05",
+	"1/2/06 15:4",
+	// 2023-07-04T09:36:33
+	"2006-01-02T15:04:05",
+	// 2023-07-04T09:36:33.2961605775
+	"2006-01-02T15:04:05.999999999",
+	// 2023-07-04T09:36:33+08:00, 2023-07-04T09:36:33.123Z
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"2006/1/2",
+	// 2023年8月7日
+	"2006年1月2日 15:04:05",
+	"2006年1月2日 15时4分5秒",
+	"2006年1月2日 15:04",
+	"2006年1月2日",
+	"2006年1月",
+}
+
+// TimeParser 可配置的时间解析, 零值按 DefaultTimeLayouts 在 time.Local 中解析
+type TimeParser struct {
+	// Layouts 按顺序尝试的格式, 为空时使用 DefaultTimeLayouts
+	Layouts []string
+	// Location 不带时区的格式使用的时区, 默认 time.Local;
+	// 带时区的格式(如 RFC3339)以字符串中的偏移为准
+	Location *time.Location
+	// Unix 纯数字字符串按时间戳解析, 优先于 Layouts
+	Unix UnixUnit
+}
+
+// WithTimeParser 使用 p 解析 time.Time
+func WithTimeParser(p *TimeParser) ParseOpt {
+	return func(o *ParseOptions) {
+		o.timeParser = p
+	}
+}
+
+// Parse 空字符串和 0000-00-00 00:00:00 解析为 1970-01-01,
+// 其它不匹配任何格式的字符串返回 ErrTimeFormat
+func (p *TimeParser) Parse(strVal string) (time.Time, error) {
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	if strVal == "" || strVal == "0000-00-00 00:00:00" {
+		return time.Date(1970, 1, 1, 0, 0, 0, 0, loc), nil
+	}
+
+	if p.Unix != UnixNone && isInteger(strVal) {
+		n, err := strconv.ParseInt(strVal, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "unix timestamp %s", strVal)
+		}
+		if p.Unix == UnixMillis {
+			return time.UnixMilli(n).In(loc), nil
+		}
+		return time.Unix(n, 0).In(loc), nil
+	}
+
+	layouts := p.Layouts
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, strVal, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.Wrapf(ErrTimeFormat, "val=%s", strVal)
+}
+
+// parseTime 使用 WithTimeParser 指定的解析器
+func (o *ParseOptions) parseTime(strVal string) (time.Time, error) {
+	if o.timeParser != nil {
+		return o.timeParser.Parse(strVal)
+	}
+	return (&TimeParser{}).Parse(strVal)
+}
+
+// WithDurationUnit 不带单位的整数按 unit 解析为 time.Duration, 如 WithDurationUnit(time.Second) 时 90 为 90s
+func WithDurationUnit(unit time.Duration) ParseOpt {
+	return func(o *ParseOptions) {
+		o.durationUnit = unit
+	}
+}
+
+// parseDuration 接受 time.ParseDuration 的格式(如 1h30m, 90s);
+// 不带单位的整数只在 WithDurationUnit 时接受
+func (o *ParseOptions) parseDuration(strVal string) (time.Duration, error) {
+	if strVal == "" {
+		return 0, nil
+	}
+	if o.durationUnit != 0 && isInteger(strVal) {
+		n, err := strconv.ParseInt(strVal, 10, 64)
+		if isRangeError(err) {
+			return 0, errors.Wrapf(ErrNumberOverflow, "val=%s", strVal)
+		}
+		if err != nil {
+			return 0, errors.Wrapf(err, "val=%s", strVal)
+		}
+		if n > math.MaxInt64/int64(o.durationUnit) || n < math.MinInt64/int64(o.durationUnit) {
+			return 0, errors.Wrapf(ErrNumberOverflow, "val=%s", strVal)
+		}
+		return time.Duration(n) * o.durationUnit, nil
+	}
+	d, err := time.ParseDuration(strVal)
+	if err != nil {
+		return 0, errors.Wrapf(err, "val=%s", strVal)
+	}
+	return d, nil
+}
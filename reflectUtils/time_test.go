@@ -0,0 +1,158 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+func TestTimeParser(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+
+	Convey("default layouts", t, func() {
+		p := &TimeParser{}
+		for in, want := range map[string]time.Time{
+			"2023-08-07 00:34:00":            time.Date(2023, 8, 7, 0, 34, 0, 0, time.Local),
+			"2023/8/7 0:34:51":               time.Date(2023, 8, 7, 0, 34, 51, 0, time.Local),
+			"2023/8/7 13:04:05":              time.Date(2023, 8, 7, 13, 4, 5, 0, time.Local),
+			"2023-07-04T09:36:33.2961605775": time.Date(2023, 7, 4, 9, 36, 33, 296160577, time.Local),
+			"2023-07-04T09:36:33.961605775":  time.Date(2023, 7, 4, 9, 36, 33, 961605775, time.Local),
+			"2023-07-04T09:36:33":            time.Date(2023, 7, 4, 9, 36, 33, 0, time.Local),
+			"2023-08-07":                     time.Date(2023, 8, 7, 0, 0, 0, 0, time.Local),
+			"2023年8月7日":                      time.Date(2023, 8, 7, 0, 0, 0, 0, time.Local),
+			"2023年08月07日 12:30":              time.Date(2023, 8, 7, 12, 30, 0, 0, time.Local),
+			"2023年8月7日 12时30分15秒":            time.Date(2023, 8, 7, 12, 30, 15, 0, time.Local),
+			"2023年8月":                        time.Date(2023, 8, 1, 0, 0, 0, 0, time.Local),
+			"":                               time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local),
+		} {
+			got, err := p.Parse(in)
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, want)
+		}
+	})
+
+	Convey("RFC3339 with offset", t, func() {
+		p := &TimeParser{}
+		got, err := p.Parse("2023-08-07T08:34:00+08:00")
+		So(err, ShouldBeNil)
+		So(got.Equal(time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC)), ShouldBeTrue)
+
+		got, err = p.Parse("2023-08-07T00:34:00.123Z")
+		So(err, ShouldBeNil)
+		So(got.Equal(time.Date(2023, 8, 7, 0, 34, 0, 123000000, time.UTC)), ShouldBeTrue)
+	})
+
+	Convey("location", t, func() {
+		p := &TimeParser{Location: cst}
+		got, err := p.Parse("2023-08-07 08:34:00")
+		So(err, ShouldBeNil)
+		So(got.Equal(time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC)), ShouldBeTrue)
+
+		// 字符串中的偏移优先
+		got, err = p.Parse("2023-08-07T00:34:00Z")
+		So(err, ShouldBeNil)
+		So(got.Equal(time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC)), ShouldBeTrue)
+	})
+
+	Convey("layouts", t, func() {
+		p := &TimeParser{Layouts: []string{"02.01.2006", "20060102"}}
+		got, err := p.Parse("07.08.2023")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, time.Date(2023, 8, 7, 0, 0, 0, 0, time.Local))
+
+		got, err = p.Parse("20230807")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, time.Date(2023, 8, 7, 0, 0, 0, 0, time.Local))
+
+		// 只使用指定的格式
+		_, err = p.Parse("2023-08-07")
+		So(errors.Cause(err), ShouldEqual, ErrTimeFormat)
+	})
+
+	Convey("unix", t, func() {
+		got, err := (&TimeParser{Unix: UnixSeconds, Location: cst}).Parse("1691368440")
+		So(err, ShouldBeNil)
+		So(got.Equal(time.Date(2023, 8, 7, 0, 34, 0, 0, time.UTC)), ShouldBeTrue)
+		So(got.Location(), ShouldEqual, cst)
+
+		got, err = (&TimeParser{Unix: UnixMillis}).Parse("1691368440123")
+		So(err, ShouldBeNil)
+		So(got.Equal(time.Date(2023, 8, 7, 0, 34, 0, 123000000, time.UTC)), ShouldBeTrue)
+
+		got, err = (&TimeParser{Unix: UnixSeconds}).Parse("-1")
+		So(err, ShouldBeNil)
+		So(got.Unix(), ShouldEqual, -1)
+
+		// 未开启时纯数字按格式解析
+		_, err = (&TimeParser{}).Parse("1691368440")
+		So(errors.Cause(err), ShouldEqual, ErrTimeFormat)
+	})
+
+	Convey("unrecognized", t, func() {
+		for _, in := range []string{"yesterday", "bogus", "2023-13-01", " "} {
+			_, err := (&TimeParser{}).Parse(in)
+			So(errors.Cause(err), ShouldEqual, ErrTimeFormat)
+		}
+
+		got, err := (&TimeParser{}).Parse("0000-00-00 00:00:00")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local))
+	})
+
+	Convey("ParseStrToInstance", t, func() {
+		got, err := ParseStrToInstance(reflect.ValueOf(time.Time{}), "1691368440", WithTimeParser(&TimeParser{Unix: UnixSeconds}))
+		So(err, ShouldBeNil)
+		So(got.Interface().(time.Time).Unix(), ShouldEqual, 1691368440)
+
+		var tp *time.Time
+		got, err = ParseStrToInstance(reflect.ValueOf(tp), "2023年8月7日")
+		So(err, ShouldBeNil)
+		So(*got.Interface().(*time.Time), ShouldEqual, time.Date(2023, 8, 7, 0, 0, 0, 0, time.Local))
+
+		_, err = ParseStrToInstance(reflect.ValueOf(time.Time{}), "yesterday")
+		So(errors.Cause(err), ShouldEqual, ErrTimeFormat)
+		_, err = ParseStrToInstance(reflect.ValueOf(time.Time{}), "yesterday", WithTimeParser(&TimeParser{Unix: UnixSeconds}))
+		So(errors.Cause(err), ShouldEqual, ErrTimeFormat)
+	})
+
+	Convey("duration", t, func() {
+		for in, want := range map[string]time.Duration{
+			"1h30m":  90 * time.Minute,
+			"90s":    90 * time.Second,
+			"-1.5ms": -1500 * time.Microsecond,
+			"":       0,
+		} {
+			got, err := ParseStrToInstance(reflect.ValueOf(time.Duration(0)), in)
+			So(err, ShouldBeNil)
+			So(got.Interface(), ShouldEqual, want)
+		}
+
+		_, err := ParseStrToInstance(reflect.ValueOf(time.Duration(0)), "1 hour")
+		So(err, ShouldNotBeNil)
+
+		// 不带单位的整数需要 WithDurationUnit
+		_, err = ParseStrToInstance(reflect.ValueOf(time.Duration(0)), "1500")
+		So(err, ShouldNotBeNil)
+		got, err := ParseStrToInstance(reflect.ValueOf(time.Duration(0)), "1500", WithDurationUnit(time.Millisecond))
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, 1500*time.Millisecond)
+		got, err = ParseStrToInstance(reflect.ValueOf(time.Duration(0)), "90s", WithDurationUnit(time.Millisecond))
+		So(err, ShouldBeNil)
+		So(got.Interface(), ShouldEqual, 90*time.Second)
+		_, err = ParseStrToInstance(reflect.ValueOf(time.Duration(0)), "9999999999999", WithDurationUnit(time.Hour))
+		So(errors.Cause(err), ShouldEqual, ErrNumberOverflow)
+
+		var p *time.Duration
+		got, err = ParseStrToInstance(reflect.ValueOf(p), "2m")
+		So(err, ShouldBeNil)
+		So(*got.Interface().(*time.Duration), ShouldEqual, 2*time.Minute)
+
+		s, err := FormatInstanceToStr(reflect.ValueOf(90 * time.Minute))
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, "1h30m0s")
+		So(quick.Check(roundTrip[time.Duration], nil), ShouldBeNil)
+	})
+}
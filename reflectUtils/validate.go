@@ -0,0 +1,470 @@
+package reflectUtils
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidateTag 校验规则使用的 tag, 如 `validate:"required,min=3,max=10"`
+const ValidateTag = "validate"
+
+// RuleFunc 校验规则, v 为解引用后的值, param 为 = 后的参数
+type RuleFunc func(v reflect.Value, param string) bool
+
+// ValidationError 一个字段未通过一条规则
+type ValidationError struct {
+	// Field 字段路径, 如 Items[2].Price, 可直接用于 Get
+	Field string
+	Rule  string
+	Param string
+	Value interface{}
+	// Message 由 Messages 中该规则的模板生成
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors 所有未通过的字段, 按字段顺序排列, 每个字段只记录第一条未通过的规则
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, ve := range e {
+		msgs = append(msgs, ve.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ByField 字段路径 -> 错误
+func (e ValidationErrors) ByField() map[string]*ValidationError {
+	res := make(map[string]*ValidationError, len(e))
+	for _, ve := range e {
+		res[ve.Field] = ve
+	}
+	return res
+}
+
+// Messages 规则名 -> 错误信息模板, 模板中可以使用 {field} {rule} {param} {value};
+// 没有对应规则时使用 key 为空字符串的模板
+type Messages map[string]string
+
+// DefaultMessages 默认的英文模板
+var DefaultMessages = Messages{
+	"":         "{field} failed on the {rule} rule",
+	"required": "{field} is required",
+	"min":      "{field} must be at least {param}",
+	"max":      "{field} must be at most {param}",
+	"len":      "{field} must have length {param}",
+	"oneof":    "{field} must be one of [{param}]",
+	"regex":    "{field} must match {param}",
+	"email":    "{field} must be a valid email address",
+	"url":      "{field} must be a valid URL",
+	"ip":       "{field} must be a valid IP address",
+}
+
+// MessagesZH 中文模板
+var MessagesZH = Messages{
+	"":         "{field} 未通过 {rule} 校验",
+	"required": "{field} 不能为空",
+	"min":      "{field} 不能小于 {param}",
+	"max":      "{field} 不能大于 {param}",
+	"len":      "{field} 的长度必须为 {param}",
+	"oneof":    "{field} 必须是 [{param}] 之一",
+	"regex":    "{field} 的格式不正确",
+	"email":    "{field} 不是有效的邮箱地址",
+	"url":      "{field} 不是有效的 URL",
+	"ip":       "{field} 不是有效的 IP 地址",
+}
+
+// ValidateOptions Validate 的选项
+type ValidateOptions struct {
+	messages Messages
+	// nameTag 字段路径中使用该 tag 的名字, 为空时使用字段名
+	nameTag string
+}
+
+type ValidateOpt func(o *ValidateOptions)
+
+// WithMessages 使用 m 中的模板, m 中没有的规则使用 DefaultMessages
+func WithMessages(m Messages) ValidateOpt {
+	return func(o *ValidateOptions) {
+		o.messages = m
+	}
+}
+
+// WithFieldNameTag 错误中的字段路径使用 tag 的名字, 如 json
+func WithFieldNameTag(tag string) ValidateOpt {
+	return func(o *ValidateOptions) {
+		o.nameTag = tag
+	}
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]RuleFunc{
+		"min":   ruleMin,
+		"max":   ruleMax,
+		"len":   ruleLen,
+		"oneof": ruleOneOf,
+		"email": ruleEmail,
+		"url":   ruleURL,
+		"ip":    ruleIP,
+	}
+	// ruleSetCache tag -> *ruleSet
+	ruleSetCache sync.Map
+)
+
+// RegisterRule 注册自定义规则, 已存在时覆盖; 内置的 required/omitempty/dive/regex 不能覆盖
+func RegisterRule(name string, fn RuleFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = fn
+	ruleSetCache.Range(func(key, _ interface{}) bool {
+		ruleSetCache.Delete(key)
+		return true
+	})
+}
+
+// Validate 按 validate tag 校验结构体(或其指针), 规则以逗号分隔, 参数中的逗号写作 \,:
+//
+//	required      非零值; 指针非 nil, string/slice/map 长度大于 0
+//	omitempty     为空时跳过其余规则
+//	min=n, max=n  数字比较大小, string(按字符)/slice/map/array 比较长度
+//	len=n         string/slice/map/array 的长度
+//	oneof=a b c   值(FormatInstanceToStr)为其中之一
+//	regex=^\d+$   string 匹配正则
+//	email, url, ip
+//	dive          之后的规则作用于 slice/array/map 的每个元素
+//
+// 嵌套的结构体(含指针、slice/array/map 中的结构体)会递归校验, 指针的环只校验一次.
+// 未通过时返回 ValidationErrors, tag 写错时返回其它错误
+func Validate(v interface{}, opts ...ValidateOpt) error {
+	o := &ValidateOptions{messages: DefaultMessages}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return errors.Errorf("Validate: v must be a struct or struct pointer, got %T", v)
+	}
+
+	vd := &validator{o: o, visited: map[visit]bool{}}
+	vd.validateStruct(val, "")
+	if vd.err != nil {
+		return vd.err
+	}
+	if len(vd.errs) > 0 {
+		return vd.errs
+	}
+	return nil
+}
+
+type rule struct {
+	name  string
+	param string
+	fn    RuleFunc
+	re    *regexp.Regexp
+}
+
+// ruleSet 一个字段的规则, dive 之后的规则作用于元素
+type ruleSet struct {
+	rules []rule
+	dive  *ruleSet
+}
+
+func parseRuleSet(tag string) (*ruleSet, error) {
+	if rs, ok := ruleSetCache.Load(tag); ok {
+		return rs.(*ruleSet), nil
+	}
+
+	root := &ruleSet{}
+	rs := root
+	for _, item := range splitRules(tag) {
+		name, param, _ := strings.Cut(item, "=")
+		r := rule{name: strings.TrimSpace(name), param: param}
+		switch r.name {
+		case "":
+			continue
+		case "dive":
+			rs.dive = &ruleSet{}
+			rs = rs.dive
+			continue
+		case "required", "omitempty":
+		case "regex":
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %s", item)
+			}
+			r.re = re
+		default:
+			rulesMu.RLock()
+			fn, exist := rules[r.name]
+			rulesMu.RUnlock()
+			if !exist {
+				return nil, errors.Errorf("unknown rule %s", r.name)
+			}
+			r.fn = fn
+		}
+		rs.rules = append(rs.rules, r)
+	}
+
+	ruleSetCache.Store(tag, root)
+	return root, nil
+}
+
+// splitRules 按逗号分割, \, 表示逗号本身
+func splitRules(tag string) []string {
+	res := make([]string, 0, 4)
+	b := strings.Builder{}
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			b.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			res = append(res, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(tag[i])
+		}
+	}
+	return append(res, b.String())
+}
+
+type validator struct {
+	o *ValidateOptions
+	// visited 已校验的结构体指针, 避免环
+	visited map[visit]bool
+	errs    ValidationErrors
+	// err tag 错误, 遇到后停止
+	err error
+}
+
+func (vd *validator) validateStruct(v reflect.Value, path string) {
+	for _, f := range CachedFields(v.Type(), vd.o.nameTag).Fields {
+		if vd.err != nil {
+			return
+		}
+		tag := f.Field.Tag.Get(ValidateTag)
+		if tag == "-" {
+			continue
+		}
+		p := joinPath(path, f.Name)
+		rs, err := parseRuleSet(tag)
+		if err != nil {
+			vd.err = errors.Wrapf(err, "field %s", p)
+			return
+		}
+		vd.check(v.FieldByIndex(f.Index), p, rs)
+	}
+}
+
+// check 校验 v 本身, 再进入元素或嵌套的结构体
+func (vd *validator) check(v reflect.Value, path string, rs *ruleSet) {
+	target := indirect(v)
+	for _, r := range rs.rules {
+		switch r.name {
+		case "omitempty":
+			if !target.IsValid() || isEmptyValue(target) {
+				return
+			}
+			continue
+		case "required":
+			if hasValue(v) {
+				continue
+			}
+		default:
+			if !target.IsValid() || vd.pass(r, target) {
+				continue
+			}
+		}
+		vd.add(path, r, target)
+		return
+	}
+
+	if !target.IsValid() {
+		return
+	}
+	if target.Kind() == reflect.Struct {
+		if isLeafType(target.Type()) {
+			return
+		}
+		if v.Kind() == reflect.Pointer {
+			// 结构体与其第一个字段地址相同, 按地址和类型区分
+			key := visit{a: v.Pointer(), typ: v.Type()}
+			if vd.visited[key] {
+				return
+			}
+			vd.visited[key] = true
+		}
+		vd.validateStruct(target, path)
+		return
+	}
+
+	elemRules := rs.dive
+	if elemRules == nil {
+		// 没有 dive 时只校验元素中的结构体
+		elemRules = &ruleSet{}
+	}
+	switch target.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < target.Len(); i++ {
+			vd.check(target.Index(i), path+"["+strconv.Itoa(i)+"]", elemRules)
+		}
+	case reflect.Map:
+		keys := target.MapKeys()
+		names := make([]string, len(keys))
+		order := make([]int, len(keys))
+		for i, k := range keys {
+			names[i], _ = FormatInstanceToStr(k)
+			order[i] = i
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return names[order[i]] < names[order[j]]
+		})
+		for _, i := range order {
+			vd.check(target.MapIndex(keys[i]), keyPath(path, names[i]), elemRules)
+		}
+	}
+}
+
+func (vd *validator) pass(r rule, v reflect.Value) bool {
+	if r.re != nil {
+		return v.Kind() == reflect.String && r.re.MatchString(v.String())
+	}
+	return r.fn(v, r.param)
+}
+
+func (vd *validator) add(path string, r rule, v reflect.Value) {
+	var value interface{}
+	if v.IsValid() && v.CanInterface() {
+		value = v.Interface()
+	}
+	tmpl, exist := vd.o.messages[r.name]
+	if !exist {
+		if tmpl, exist = DefaultMessages[r.name]; !exist {
+			if tmpl, exist = vd.o.messages[""]; !exist {
+				tmpl = DefaultMessages[""]
+			}
+		}
+	}
+	msg := strings.NewReplacer(
+		"{field}", path,
+		"{rule}", r.name,
+		"{param}", r.param,
+		"{value}", fmt.Sprint(value),
+	).Replace(tmpl)
+	vd.errs = append(vd.errs, &ValidationError{Field: path, Rule: r.name, Param: r.param, Value: value, Message: msg})
+}
+
+// indirect 解引用指针和 interface, nil 时返回无效值
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func hasValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() > 0
+	}
+	return !v.IsZero()
+}
+
+// size 数字的值, string 的字符数, slice/map/array 的长度
+func size(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+func compareSize(v reflect.Value, param string, ok func(n, limit float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	n, valid := size(v)
+	return valid && ok(n, limit)
+}
+
+func ruleMin(v reflect.Value, param string) bool {
+	return compareSize(v, param, func(n, limit float64) bool { return n >= limit })
+}
+
+func ruleMax(v reflect.Value, param string) bool {
+	return compareSize(v, param, func(n, limit float64) bool { return n <= limit })
+}
+
+func ruleLen(v reflect.Value, param string) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return compareSize(v, param, func(n, limit float64) bool { return n == limit })
+	}
+	return false
+}
+
+func ruleOneOf(v reflect.Value, param string) bool {
+	s, err := FormatInstanceToStr(v)
+	if err != nil {
+		return false
+	}
+	for _, option := range strings.Fields(param) {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleEmail(v reflect.Value, _ string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	addr, err := mail.ParseAddress(v.String())
+	return err == nil && addr.Address == v.String()
+}
+
+func ruleURL(v reflect.Value, _ string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	u, err := url.ParseRequestURI(v.String())
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func ruleIP(v reflect.Value, _ string) bool {
+	return v.Kind() == reflect.String && net.ParseIP(v.String()) != nil
+}
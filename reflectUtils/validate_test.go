@@ -0,0 +1,182 @@
+package reflectUtils
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validItem struct {
+	Sku   string  `json:"sku" validate:"required,regex=^[A-Z]{2\\,4}-\\d+$"`
+	Price float64 `json:"price" validate:"min=0.01,max=10000"`
+}
+
+type validOrder struct {
+	Id       int                  `json:"id" validate:"required"`
+	Customer string               `json:"customer" validate:"required,min=2,max=8"`
+	Email    string               `json:"email" validate:"omitempty,email"`
+	Site     string               `json:"site" validate:"omitempty,url"`
+	Ip       *string              `json:"ip" validate:"omitempty,ip"`
+	Status   string               `json:"status" validate:"oneof=new paid shipped"`
+	Level    level                `json:"level" validate:"oneof=low high"`
+	Code     string               `json:"code" validate:"len=4"`
+	Items    []validItem          `json:"items" validate:"required,max=3"`
+	Tags     []string             `json:"tags" validate:"max=2,dive,required,max=3"`
+	Scores   map[string]int       `json:"scores" validate:"dive,min=0,max=100"`
+	Matrix   [][]int              `json:"matrix" validate:"dive,len=2,dive,min=1"`
+	Parent   *validOrder          `json:"parent"`
+	Extra    map[string]validItem `json:"extra"`
+	Ignored  string               `json:"ignored" validate:"-"`
+}
+
+func newValidOrder() *validOrder {
+	return &validOrder{
+		Id:       1,
+		Customer: "tom",
+		Email:    "tom@example.com",
+		Site:     "https://example.com/a",
+		Status:   "paid",
+		Level:    level(2),
+		Code:     "ab中c",
+		Items:    []validItem{{Sku: "AB-1", Price: 1}},
+		Tags:     []string{"a", "bcd"},
+		Scores:   map[string]int{"math": 90},
+		Matrix:   [][]int{{1, 2}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	Convey("valid", t, func() {
+		o := newValidOrder()
+		o.Parent = o
+		So(Validate(o), ShouldBeNil)
+		So(Validate(*o), ShouldBeNil)
+	})
+
+	Convey("invalid", t, func() {
+		ip := "300.1.1.1"
+		o := &validOrder{
+			Customer: "t",
+			Email:    "tom",
+			Site:     "example.com",
+			Ip:       &ip,
+			Status:   "lost",
+			Level:    level(3),
+			Code:     "abc",
+			Items:    []validItem{{Sku: "ab-1", Price: 1}, {Sku: "ABCDE-1", Price: 0}},
+			Tags:     []string{"", "abcd"},
+			Scores:   map[string]int{"math": 101, "art": -1},
+			Matrix:   [][]int{{1}, {0, 1}},
+			Parent:   &validOrder{Id: 2, Customer: "jo", Status: "new", Level: level(1), Code: "abcd", Items: []validItem{{Sku: "AB-2", Price: 1}}},
+			Extra:    map[string]validItem{"x": {}},
+			Ignored:  "",
+		}
+		err := Validate(o)
+		errs, ok := err.(ValidationErrors)
+		So(ok, ShouldBeTrue)
+
+		got := make([]string, 0, len(errs))
+		for _, e := range errs {
+			got = append(got, e.Field+":"+e.Rule)
+		}
+		So(got, ShouldResemble, []string{
+			"Id:required",
+			"Customer:min",
+			"Email:email",
+			"Site:url",
+			"Ip:ip",
+			"Status:oneof",
+			"Level:oneof",
+			"Code:len",
+			"Items[0].Sku:regex",
+			"Items[1].Sku:regex",
+			"Items[1].Price:min",
+			"Tags[0]:required",
+			"Tags[1]:max",
+			"Scores.art:min",
+			"Scores.math:max",
+			"Matrix[0]:len",
+			"Matrix[1][0]:min",
+			"Extra.x.Sku:required",
+			"Extra.x.Price:min",
+		})
+
+		byField := errs.ByField()
+		So(byField["Customer"].Message, ShouldEqual, "Customer must be at least 2")
+		So(byField["Customer"].Value, ShouldEqual, "t")
+		So(byField["Status"].Message, ShouldEqual, "Status must be one of [new paid shipped]")
+		So(byField["Scores.math"].Value, ShouldEqual, 101)
+		So(strings.Contains(err.Error(), "; "), ShouldBeTrue)
+
+		Convey("messages", func() {
+			err := Validate(o, WithMessages(MessagesZH), WithFieldNameTag("json"))
+			byField := err.(ValidationErrors).ByField()
+			So(byField["id"].Message, ShouldEqual, "id 不能为空")
+			So(byField["items[1].price"].Message, ShouldEqual, "items[1].price 不能小于 0.01")
+
+			err = Validate(o, WithMessages(Messages{"min": "{field}={value}, min {param}"}))
+			byField = err.(ValidationErrors).ByField()
+			So(byField["Customer"].Message, ShouldEqual, "Customer=t, min 2")
+			So(byField["Id"].Message, ShouldEqual, "Id is required")
+		})
+	})
+
+	Convey("paths", t, func() {
+		type inner struct {
+			Name string `validate:"required"`
+		}
+		type outer struct {
+			In    inner
+			Next  *outer
+			First *inner
+			Items map[string]inner
+		}
+		next := &outer{}
+		// First 与 Next 地址相同, 类型不同
+		next.First = &next.In
+		v := outer{In: inner{Name: "x"}, Next: next, Items: map[string]inner{"a.b": {}, "": {}}}
+
+		errs := Validate(v).(ValidationErrors)
+		got := make([]string, 0, len(errs))
+		for _, e := range errs {
+			got = append(got, e.Field)
+		}
+		So(got, ShouldResemble, []string{
+			"Next.In.Name",
+			"Next.First.Name",
+			`Items[""].Name`,
+			`Items["a.b"].Name`,
+		})
+	})
+
+	Convey("custom rule", t, func() {
+		RegisterRule("even", func(v reflect.Value, _ string) bool {
+			return v.Kind() == reflect.Int && v.Int()%2 == 0
+		})
+		v := struct {
+			N int `validate:"even"`
+		}{N: 3}
+		err := Validate(&v, WithMessages(Messages{"": "{field} is not {rule}"}))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "N is not even")
+		v.N = 4
+		So(Validate(&v), ShouldBeNil)
+	})
+
+	Convey("bad tag", t, func() {
+		v := struct {
+			A string `validate:"unknown"`
+		}{}
+		err := Validate(v)
+		So(err, ShouldNotBeNil)
+		_, ok := err.(ValidationErrors)
+		So(ok, ShouldBeFalse)
+
+		w := struct {
+			A string `validate:"regex=("`
+		}{}
+		So(Validate(w), ShouldNotBeNil)
+		So(Validate(1), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,511 @@
+package reflectUtils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDiscriminator json 中判别具体类型的默认 key, 如 {"type":"card",...}
+const DefaultDiscriminator = "type"
+
+// ErrUnknownVariant 判别值或具体类型没有注册
+var ErrUnknownVariant = errors.New("unknown variant")
+
+// variantSet 一个接口类型注册的所有实现, 注册时复制后整体替换, 取出后可以不加锁读取
+type variantSet struct {
+	key    string
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}
+
+var (
+	variantsMu sync.RWMutex
+	// variants 接口类型 -> *variantSet
+	variants = map[reflect.Type]*variantSet{}
+	// variantsGen 注册表的版本, 在 variantsMu 下读写
+	variantsGen uint64
+)
+
+type variantCacheKey struct {
+	typ reflect.Type
+	// gen 计算时的 variantsGen, 注册之后旧的结果不再被命中
+	gen uint64
+}
+
+// variantCache variantCacheKey -> containsVariant 的结果
+var variantCache sync.Map
+
+// RegisterVariant 注册接口类型 I 的一个实现, name 为 json 中的判别值, sample 为实现类型的任意值,
+// 实现类型需要是 struct 或 struct 的指针, 例如:
+//
+//	reflectUtils.RegisterVariant[PaymentMethod]("card", Card{})
+//	reflectUtils.RegisterVariant[PaymentMethod]("wallet", &Wallet{})
+//
+// 之后 ParseStrToInstance 把 {"type":"card","no":"6222"} 解析为 PaymentMethod(Card{...}),
+// FormatInstanceToStr 输出时加上判别字段. 同一个 name 或实现类型重复注册时覆盖
+func RegisterVariant[I any](name string, sample I) {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(errors.Errorf("RegisterVariant: %s is not an interface", iface))
+	}
+	concrete := reflect.TypeOf(sample)
+	if concrete == nil {
+		panic(errors.Errorf("RegisterVariant(%s, %s): nil sample", iface, name))
+	}
+	if concrete.Kind() != reflect.Struct && (concrete.Kind() != reflect.Pointer || concrete.Elem().Kind() != reflect.Struct) {
+		panic(errors.Errorf("RegisterVariant(%s, %s): %s is not a struct or struct pointer", iface, name, concrete))
+	}
+
+	updateVariants(iface, func(s *variantSet) {
+		if old, ok := s.byName[name]; ok {
+			delete(s.byType, old)
+		}
+		if old, ok := s.byType[concrete]; ok {
+			delete(s.byName, old)
+		}
+		s.byName[name] = concrete
+		s.byType[concrete] = name
+	})
+}
+
+// SetDiscriminator 接口类型 I 的判别字段的 json key, 默认 DefaultDiscriminator
+func SetDiscriminator[I any](key string) {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	updateVariants(iface, func(s *variantSet) {
+		s.key = key
+	})
+}
+
+func updateVariants(iface reflect.Type, update func(s *variantSet)) {
+	variantsMu.Lock()
+	defer variantsMu.Unlock()
+	s := &variantSet{key: DefaultDiscriminator, byName: map[string]reflect.Type{}, byType: map[reflect.Type]string{}}
+	if old, ok := variants[iface]; ok {
+		s.key = old.key
+		for k, v := range old.byName {
+			s.byName[k] = v
+		}
+		for k, v := range old.byType {
+			s.byType[k] = v
+		}
+	}
+	update(s)
+	variants[iface] = s
+
+	variantsGen++
+	gen := variantsGen
+	variantCache.Range(func(key, _ interface{}) bool {
+		if key.(variantCacheKey).gen != gen {
+			variantCache.Delete(key)
+		}
+		return true
+	})
+}
+
+func lookupVariants(iface reflect.Type) (*variantSet, bool) {
+	variantsMu.RLock()
+	defer variantsMu.RUnlock()
+	s, ok := variants[iface]
+	return s, ok && len(s.byName) > 0
+}
+
+// HasVariants 接口类型 typ 是否通过 RegisterVariant 注册了实现
+func HasVariants(typ reflect.Type) bool {
+	_, ok := lookupVariants(typ)
+	return ok
+}
+
+// parseVariant 按判别字段选择实现类型, 再把整个 json 对象解析为该类型
+func parseVariant(iface reflect.Type, strVal string) (reflect.Value, error) {
+	s, ok := lookupVariants(iface)
+	if !ok {
+		return reflect.Value{}, errors.Errorf("ParseStrToInstance(%s,%s) not support interface without RegisterVariant", iface, strVal)
+	}
+	if strVal == "" || strVal == "null" {
+		return reflect.Zero(iface), nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(strVal), &fields); err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "%s val=%s", iface, strVal)
+	}
+	raw, ok := fields[s.key]
+	if !ok {
+		return reflect.Value{}, errors.Wrapf(ErrUnknownVariant, "%s: missing %q, val=%s", iface, s.key, strVal)
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "%s: %q, val=%s", iface, s.key, strVal)
+	}
+	concrete, ok := s.byName[name]
+	if !ok {
+		return reflect.Value{}, errors.Wrapf(ErrUnknownVariant, "%s: %s=%s", iface, s.key, name)
+	}
+
+	v, err := unmarshalJSON(concrete, []byte(strVal))
+	if err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "%s val=%s", concrete, strVal)
+	}
+
+	res := reflect.New(iface).Elem()
+	res.Set(v)
+	return res, nil
+}
+
+// formatVariant 具体值的 json 加上判别字段; 具体值自身已输出判别字段时不再添加.
+// v 为非 nil 的接口值, ok=false 表示接口没有注册实现
+func (o *FormatOptions) formatVariant(v reflect.Value) (str string, ok bool, err error) {
+	s, ok := lookupVariants(v.Type())
+	if !ok {
+		return "", false, nil
+	}
+	elem := v.Elem()
+	name, registered := s.byType[elem.Type()]
+	if !registered {
+		return "", true, errors.Wrapf(ErrUnknownVariant, "%s: %s", v.Type(), elem.Type())
+	}
+	if elem.Kind() == reflect.Pointer && elem.IsNil() {
+		return o.nilStr, true, nil
+	}
+
+	b, err := o.marshalJSON(elem)
+	if err != nil {
+		return "", true, errors.Wrapf(err, "json.Marshal(%s)", elem.Type())
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", true, errors.Wrapf(err, "%s is not a json object", elem.Type())
+	}
+	if _, exist := fields[s.key]; exist {
+		return string(b), true, nil
+	}
+
+	key, _ := json.Marshal(s.key)
+	val, _ := json.Marshal(name)
+	res := append([]byte{'{'}, key...)
+	res = append(res, ':')
+	res = append(res, val...)
+	if len(fields) > 0 {
+		res = append(res, ',')
+	}
+	return string(append(res, b[1:]...)), true, nil
+}
+
+// containsVariant typ 的 json 中(slice/array/map 的元素、指针、结构体字段)是否有注册了实现的接口,
+// 自身实现了 json/text 编解码接口的类型由其自身处理. 结果按类型缓存, RegisterVariant 后重新计算
+func containsVariant(typ reflect.Type) bool {
+	variantsMu.RLock()
+	empty := len(variants) == 0
+	gen := variantsGen
+	variantsMu.RUnlock()
+	if empty {
+		return false
+	}
+
+	key := variantCacheKey{typ: typ, gen: gen}
+	if ok, exist := variantCache.Load(key); exist {
+		return ok.(bool)
+	}
+	ok := containsVariantIn(typ, map[reflect.Type]bool{})
+	variantCache.Store(key, ok)
+	return ok
+}
+
+func containsVariantIn(typ reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[typ] {
+		return false
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	if typ.Kind() == reflect.Interface {
+		return HasVariants(typ)
+	}
+	if typ.Kind() != reflect.Pointer {
+		ptr := reflect.PtrTo(typ)
+		if ptr.Implements(jsonMarshalerType) || ptr.Implements(jsonUnmarshalerType) ||
+			ptr.Implements(textMarshalerType) || ptr.Implements(textUnmarshalerType) {
+			return false
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return containsVariantIn(typ.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			if (sf.IsExported() || sf.Anonymous) && sf.Tag.Get("json") != "-" && containsVariantIn(sf.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// variantField 结构体中含有注册了实现的接口的字段, name 为 json 中的 key
+type variantField struct {
+	name  string
+	index []int
+}
+
+// variantFields 按 encoding/json 的规则取字段的 key, 进入未指定 tag 的匿名结构体(非指针)
+func variantFields(typ reflect.Type, index []int) []variantField {
+	var res []variantField
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" || !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		idx := append(append([]int{}, index...), i)
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			res = append(res, variantFields(sf.Type, idx)...)
+			continue
+		}
+		if !sf.IsExported() || !containsVariant(sf.Type) {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		res = append(res, variantField{name: name, index: idx})
+	}
+	return res
+}
+
+// unmarshalJSON 同 json.Unmarshal, 但嵌套的注册了实现的接口按判别字段解析
+func unmarshalJSON(typ reflect.Type, data []byte) (reflect.Value, error) {
+	if !containsVariant(typ) {
+		v := reflect.New(typ)
+		if err := json.Unmarshal(data, v.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+	if string(bytes.TrimSpace(data)) == "null" {
+		return reflect.Zero(typ), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		return parseVariant(typ, string(data))
+	case reflect.Pointer:
+		elem, err := unmarshalJSON(typ.Elem(), data)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ.Elem())
+		v.Elem().Set(elem)
+		return v, nil
+	case reflect.Slice, reflect.Array:
+		var raws []json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return reflect.Value{}, err
+		}
+		var v reflect.Value
+		if typ.Kind() == reflect.Array {
+			if len(raws) != typ.Len() {
+				return reflect.Value{}, errors.Wrapf(ErrMalformed, "%s needs %d elements, got %d", typ, typ.Len(), len(raws))
+			}
+			v = reflect.New(typ).Elem()
+		} else {
+			v = reflect.MakeSlice(typ, len(raws), len(raws))
+		}
+		for i, raw := range raws {
+			elem, err := unmarshalJSON(typ.Elem(), raw)
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "[%d]", i)
+			}
+			v.Index(i).Set(elem)
+		}
+		return v, nil
+	case reflect.Map:
+		raws := reflect.New(reflect.MapOf(typ.Key(), rawMessageType))
+		if err := json.Unmarshal(data, raws.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.MakeMapWithSize(typ, raws.Elem().Len())
+		iter := raws.Elem().MapRange()
+		for iter.Next() {
+			elem, err := unmarshalJSON(typ.Elem(), iter.Value().Bytes())
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "[%v]", iter.Key())
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+		return v, nil
+	case reflect.Struct:
+		raws := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ).Elem()
+		for _, f := range variantFields(typ, nil) {
+			key, ok := matchJSONKey(raws, f.name)
+			if !ok {
+				continue
+			}
+			elem, err := unmarshalJSON(v.FieldByIndex(f.index).Type(), raws[key])
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "%s", f.name)
+			}
+			v.FieldByIndex(f.index).Set(elem)
+			delete(raws, key)
+		}
+		// 其余字段交给 encoding/json
+		rest, _ := json.Marshal(raws)
+		if err := json.Unmarshal(rest, v.Addr().Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return v, nil
+	}
+	return reflect.Value{}, errors.Errorf("unmarshal %s: not support %s", typ, typ.Kind())
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// matchJSONKey 与 encoding/json 相同, 优先完全匹配, 其次忽略大小写匹配
+func matchJSONKey(raws map[string]json.RawMessage, name string) (string, bool) {
+	if _, ok := raws[name]; ok {
+		return name, true
+	}
+	for k := range raws {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// marshalJSON 同 json.Marshal, 但嵌套的注册了实现的接口输出判别字段
+func (o *FormatOptions) marshalJSON(v reflect.Value) ([]byte, error) {
+	if !containsVariant(v.Type()) {
+		return json.Marshal(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		s, _, err := o.formatVariant(v)
+		if err != nil {
+			return nil, err
+		}
+		if v.Elem().Kind() == reflect.Pointer && v.Elem().IsNil() {
+			return []byte("null"), nil
+		}
+		return []byte(s), nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		return o.marshalJSON(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []byte("null"), nil
+		}
+		res := []byte{'['}
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				res = append(res, ',')
+			}
+			b, err := o.marshalJSON(v.Index(i))
+			if err != nil {
+				return nil, errors.Wrapf(err, "[%d]", i)
+			}
+			res = append(res, b...)
+		}
+		return append(res, ']'), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		// 借助 map[string]json.RawMessage 得到与 encoding/json 相同的 key 顺序和转义
+		raws := make(map[string]json.RawMessage, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := jsonMapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			b, err := o.marshalJSON(iter.Value())
+			if err != nil {
+				return nil, errors.Wrapf(err, "[%s]", key)
+			}
+			raws[key] = b
+		}
+		return json.Marshal(raws)
+	case reflect.Struct:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		repl := map[string]json.RawMessage{}
+		for _, f := range variantFields(v.Type(), nil) {
+			fv, err := v.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			if repl[f.name], err = o.marshalJSON(fv); err != nil {
+				return nil, errors.Wrapf(err, "%s", f.name)
+			}
+		}
+		return replaceJSONFields(b, repl)
+	}
+	return json.Marshal(v.Interface())
+}
+
+// jsonMapKey 与 encoding/json 相同: string、TextMarshaler、整数作为 key
+func jsonMapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", errors.Errorf("unsupported map key type %s", k.Type())
+}
+
+// replaceJSONFields 按原顺序输出 json 对象 b, repl 中的 key 使用 repl 中的值
+func replaceJSONFields(b []byte, repl map[string]json.RawMessage) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	res := []byte{'{'}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		key := t.(string)
+		if r, ok := repl[key]; ok {
+			raw = r
+		}
+		if len(res) > 1 {
+			res = append(res, ',')
+		}
+		k, _ := json.Marshal(key)
+		res = append(append(append(res, k...), ':'), raw...)
+	}
+	return append(res, '}'), nil
+}
@@ -0,0 +1,202 @@
+package reflectUtils
+
+import (
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"reflect"
+	"testing"
+)
+
+type payMethod interface {
+	Pay(amount float64) string
+}
+
+type payCard struct {
+	No string `json:"no"`
+}
+
+func (c payCard) Pay(float64) string { return "card " + c.No }
+
+type payWallet struct {
+	Account string `json:"account"`
+}
+
+func (w *payWallet) Pay(float64) string { return "wallet " + w.Account }
+
+type payCash struct{}
+
+func (payCash) Pay(float64) string { return "cash" }
+
+type shape interface{}
+
+type shapeCircle struct {
+	Kind string  `json:"kind"`
+	R    float64 `json:"r"`
+}
+
+// lateVariant 在测试中才注册实现
+type lateVariant interface{ Late() }
+
+type lateImpl struct{}
+
+func (lateImpl) Late() {}
+
+type payOrder struct {
+	Id      int       `json:"id"`
+	Payment payMethod `json:"payment"`
+}
+
+func init() {
+	RegisterVariant[payMethod]("card", payCard{})
+	RegisterVariant[payMethod]("wallet", &payWallet{})
+	RegisterVariant[payMethod]("cash", payCash{})
+
+	SetDiscriminator[shape]("kind")
+	RegisterVariant[shape]("circle", shapeCircle{})
+}
+
+func TestVariant(t *testing.T) {
+	typ := reflect.TypeOf((*payMethod)(nil)).Elem()
+	zero := reflect.Zero(typ)
+
+	Convey("parse", t, func() {
+		v, err := ParseStrToInstance(zero, `{"type":"card","no":"6222"}`)
+		So(err, ShouldBeNil)
+		So(v.Type(), ShouldEqual, typ)
+		So(v.Interface(), ShouldResemble, payCard{No: "6222"})
+
+		v, err = ParseStrToInstance(zero, `{"account":"tom","type":"wallet"}`)
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, &payWallet{Account: "tom"})
+
+		v, err = ParseStrToInstance(zero, "")
+		So(err, ShouldBeNil)
+		So(v.IsNil(), ShouldBeTrue)
+
+		_, err = ParseStrToInstance(zero, `{"type":"bitcoin"}`)
+		So(errors.Is(err, ErrUnknownVariant), ShouldBeTrue)
+		_, err = ParseStrToInstance(zero, `{"no":"6222"}`)
+		So(errors.Is(err, ErrUnknownVariant), ShouldBeTrue)
+		_, err = ParseStrToInstance(zero, `{"type":1}`)
+		So(err, ShouldNotBeNil)
+		_, err = ParseStrToInstance(zero, `card`)
+		So(err, ShouldNotBeNil)
+
+		// 没有注册实现的接口仍不支持
+		_, err = ParseStrToInstance(reflect.Zero(reflect.TypeOf((*error)(nil)).Elem()), `{}`)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("format", t, func() {
+		var p payMethod = payCard{No: "6222"}
+		s, err := FormatInstanceToStr(reflect.ValueOf(&p).Elem())
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, `{"type":"card","no":"6222"}`)
+
+		p = payCash{}
+		s, err = FormatInstanceToStr(reflect.ValueOf(&p).Elem())
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, `{"type":"cash"}`)
+
+		// 具体值已有判别字段
+		var sh shape = shapeCircle{Kind: "circle", R: 1.5}
+		s, err = FormatInstanceToStr(reflect.ValueOf(&sh).Elem())
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, `{"kind":"circle","r":1.5}`)
+
+		p = &payWallet{Account: "tom"}
+		s, err = FormatInstanceToStr(reflect.ValueOf(&p).Elem())
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, `{"type":"wallet","account":"tom"}`)
+
+		// payCard 只注册了值类型
+		p = &payCard{No: "6222"}
+		_, err = FormatInstanceToStr(reflect.ValueOf(&p).Elem())
+		So(errors.Is(err, ErrUnknownVariant), ShouldBeTrue)
+	})
+
+	Convey("round trip", t, func() {
+		for _, p := range []payMethod{payCard{No: "1"}, &payWallet{Account: "a"}, payCash{}} {
+			s, err := FormatInstanceToStr(reflect.ValueOf(&p).Elem())
+			So(err, ShouldBeNil)
+			v, err := ParseStrToInstance(zero, s)
+			So(err, ShouldBeNil)
+			So(v.Interface(), ShouldResemble, p)
+		}
+
+		o := payOrder{Id: 1, Payment: &payWallet{Account: "tom"}}
+		m, err := Flatten(o, "json")
+		So(err, ShouldBeNil)
+		So(m["payment"], ShouldEqual, `{"type":"wallet","account":"tom"}`)
+
+		var got payOrder
+		So(Unflatten(&got, m, "json"), ShouldBeNil)
+		So(got, ShouldResemble, o)
+
+		got = payOrder{}
+		So(BindMap(&got, map[string]string{"id": "2", "payment": `{"type":"card","no":"9"}`}, "json"), ShouldBeNil)
+		So(got.Payment.Pay(1), ShouldEqual, "card 9")
+	})
+
+	Convey("nested", t, func() {
+		type cell struct {
+			P payMethod `json:"p"`
+		}
+		type embedded struct {
+			cell
+			Ps   map[string]payMethod `json:"ps,omitempty"`
+			Name string               `json:"name"`
+		}
+
+		for _, c := range []struct {
+			v   interface{}
+			str string
+		}{
+			{[]payMethod{payCard{No: "2"}, nil, &payWallet{Account: "a"}}, `[{"type":"card","no":"2"},null,{"type":"wallet","account":"a"}]`},
+			{[1]payMethod{payCash{}}, `[{"type":"cash"}]`},
+			{map[int]payMethod{2: payCard{No: "b"}, 1: payCash{}}, `{"1":{"type":"cash"},"2":{"type":"card","no":"b"}}`},
+			{cell{P: payCard{No: "c"}}, `{"p":{"type":"card","no":"c"}}`},
+			{&cell{}, `{"p":null}`},
+			{embedded{cell: cell{P: payCash{}}, Name: "x"}, `{"p":{"type":"cash"},"name":"x"}`},
+			{[]cell{{P: &payWallet{Account: "d"}}}, `[{"p":{"type":"wallet","account":"d"}}]`},
+		} {
+			s, err := FormatInstanceToStr(reflect.ValueOf(c.v))
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, c.str)
+
+			v, err := ParseStrToInstance(reflect.Zero(reflect.TypeOf(c.v)), c.str)
+			So(err, ShouldBeNil)
+			So(v.Interface(), ShouldResemble, c.v)
+		}
+
+		// 忽略大小写匹配 key, 与 encoding/json 相同
+		v, err := ParseStrToInstance(reflect.Zero(reflect.TypeOf(cell{})), `{"P":{"type":"cash"}}`)
+		So(err, ShouldBeNil)
+		So(v.Interface(), ShouldResemble, cell{P: payCash{}})
+
+		_, err = ParseStrToInstance(reflect.Zero(reflect.TypeOf([]payMethod{})), `[{"type":"bitcoin"}]`)
+		So(errors.Is(err, ErrUnknownVariant), ShouldBeTrue)
+		_, err = ParseStrToInstance(reflect.Zero(reflect.TypeOf([2]payMethod{})), `[{"type":"cash"}]`)
+		So(errors.Is(err, ErrMalformed), ShouldBeTrue)
+		_, err = FormatInstanceToStr(reflect.ValueOf([]payMethod{&payCard{}}))
+		So(errors.Is(err, ErrUnknownVariant), ShouldBeTrue)
+	})
+
+	Convey("register", t, func() {
+		So(HasVariants(typ), ShouldBeTrue)
+		So(HasVariants(reflect.TypeOf((*error)(nil)).Elem()), ShouldBeFalse)
+		So(func() { RegisterVariant[payCard]("x", payCard{}) }, ShouldPanic)
+		So(func() { RegisterVariant[shape]("x", 1) }, ShouldPanic)
+		So(func() { RegisterVariant[payMethod]("x", nil) }, ShouldPanic)
+
+		// containsVariant 的缓存在注册后失效
+		type holder struct {
+			L []lateVariant `json:"l"`
+		}
+		holderTyp := reflect.TypeOf(holder{})
+		So(containsVariant(holderTyp), ShouldBeFalse)
+		So(containsVariant(holderTyp), ShouldBeFalse)
+		RegisterVariant[lateVariant]("late", lateImpl{})
+		So(containsVariant(holderTyp), ShouldBeTrue)
+	})
+}